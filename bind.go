@@ -0,0 +1,266 @@
+package flip
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// A Value setting an addressable reflect.Value, used for bound struct fields
+// of types without a package flag function.
+type reflectValue struct {
+	v   reflect.Value
+	pfn func(string, reflect.Value) error
+}
+
+// Value interface Set function for internal type reflectValue
+func (r *reflectValue) Set(s string) error {
+	return r.pfn(s, r.v)
+}
+
+// Value interface Get function for internal type reflectValue
+func (r *reflectValue) Get() interface{} { return r.v.Interface() }
+
+// Value interface String function for internal type reflectValue
+func (r *reflectValue) String() string { return formatTyped(r.v.Addr().Interface()) }
+
+// TypeNamer interface TypeName function for internal type reflectValue
+func (r *reflectValue) TypeName() string { return typeName(r.v.Type()) }
+
+// internal boolFlag interface IsBoolFlag function for internal type reflectValue
+func (r *reflectValue) IsBoolFlag() bool { return r.v.Kind() == reflect.Bool }
+
+type bindTag struct {
+	name, short, usage, def, prefix, sep string
+	env, choices                         []string
+	required, fold, skip                 bool
+}
+
+var bindKeys = map[string]bool{
+	"name": true, "short": true, "usage": true, "default": true, "prefix": true,
+	"sep": true, "env": true, "choices": true,
+}
+
+// parses a flip struct tag of comma delimited key=value pairs and keywords,
+// where a part that is neither continues a preceding usage or default value
+// containing commas
+func parseBindTag(tag string) (*bindTag, error) {
+	t := &bindTag{}
+	if tag == "-" {
+		t.skip = true
+		return t, nil
+	}
+	kv := make(map[string]string)
+	last := ""
+	for _, part := range strings.Split(tag, ",") {
+		spl := strings.SplitN(part, "=", 2)
+		k := strings.TrimSpace(spl[0])
+		switch {
+		case len(spl) == 2 && bindKeys[k]:
+			kv[k] = spl[1]
+			last = k
+		case k == "required":
+			t.required = true
+		case k == "insensitive":
+			t.fold = true
+		case last == "usage" || last == "default":
+			kv[last] = kv[last] + "," + part
+		case k == "":
+		default:
+			return nil, fmt.Errorf("unknown flip tag key %q", k)
+		}
+	}
+	t.name, t.short, t.usage = kv["name"], kv["short"], kv["usage"]
+	t.def, t.prefix, t.sep = kv["default"], kv["prefix"], kv["sep"]
+	if t.sep == "comma" {
+		t.sep = ","
+	}
+	if e, ok := kv["env"]; ok {
+		t.env = strings.Split(e, "|")
+	}
+	if c, ok := kv["choices"]; ok {
+		t.choices = strings.Split(c, "|")
+	}
+	return t, nil
+}
+
+// returns a kebab cased flag name from a field name, e.g. "log-level" from
+// LogLevel and "tls-key" from TLSKey
+func kebab(s string) string {
+	r := []rune(s)
+	var b strings.Builder
+	for i, c := range r {
+		if unicode.IsUpper(c) && i > 0 {
+			prev := r[i-1]
+			next := i+1 < len(r) && unicode.IsLower(r[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next) {
+				b.WriteRune('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(c))
+	}
+	return b.String()
+}
+
+// Registers a flag on the provided *FlagSet for every exported field of the
+// struct pointed to by v, with the current field value as default. Fields are
+// configured by a `flip` struct tag of comma delimited keys:
+//
+//	name=addr       flag name, default the kebab cased field name
+//	short=a         one letter short name
+//	usage=...       usage message, may contain commas
+//	env=ADDR|ALT    environment variables, '|' delimited
+//	default=...     default value, as if set from a configuration, may contain commas
+//	choices=a|b     restrict a string field to choices
+//	insensitive     match choices regardless of case
+//	sep=;           separator of slice and map fields, "comma" for ','
+//	prefix=db-      flag name prefix of a nested struct, default the name and '-'
+//	required        mark the flag required
+//
+// and a tag of "-" skips the field. Supported fields are booleans, integers,
+// unsigned integers, floats, strings, time.Duration, slices of these, a
+// map[string]string, types implementing encoding.TextUnmarshaler or
+// encoding.BinaryUnmarshaler by pointer, and structs (or struct pointers) of
+// these, which are bound recursively. Errors are returned for an invalid v,
+// tag, or unsupported field, or a nil embedded pointer to an unexported struct
+// (which cannot be allocated), and flag definition panics as for Var.
+func Bind(fs *FlagSet, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bind requires a non nil struct pointer, got %T", v)
+	}
+	return bindStruct(fs, rv.Elem(), "")
+}
+
+func bindStruct(fs *FlagSet, sv reflect.Value, prefix string) error {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		t, err := parseBindTag(sf.Tag.Get("flip"))
+		if err != nil {
+			return fmt.Errorf("field %s: %v", sf.Name, err)
+		}
+		if t.skip {
+			continue
+		}
+		if t.name == "" {
+			t.name = kebab(sf.Name)
+		}
+		name := prefix + t.name
+		fv := sv.Field(i)
+		if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct && parserFor(fv.Type().Elem()) == nil {
+			if fv.IsNil() {
+				if sf.PkgPath != "" {
+					return fmt.Errorf("field %s: nil pointer to unexported struct", sf.Name)
+				}
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct && parserFor(fv.Type()) == nil {
+			np := prefix
+			switch {
+			case t.prefix != "":
+				np += t.prefix
+			case !sf.Anonymous:
+				np += t.name + "-"
+			}
+			if err := bindStruct(fs, fv, np); err != nil {
+				return err
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		if err := bindField(fs, fv, name, t); err != nil {
+			return fmt.Errorf("field %s: %v", sf.Name, err)
+		}
+	}
+	return nil
+}
+
+func bindField(fs *FlagSet, fv reflect.Value, name string, t *bindTag) error {
+	switch p := fv.Addr().Interface().(type) {
+	case *bool:
+		fs.BoolVar(p, name, *p, t.usage)
+	case *int:
+		fs.IntVar(p, name, *p, t.usage)
+	case *int64:
+		fs.Int64Var(p, name, *p, t.usage)
+	case *uint:
+		fs.UintVar(p, name, *p, t.usage)
+	case *uint64:
+		fs.Uint64Var(p, name, *p, t.usage)
+	case *float64:
+		fs.Float64Var(p, name, *p, t.usage)
+	case *time.Duration:
+		fs.DurationVar(p, name, *p, t.usage)
+	case *string:
+		if len(t.choices) > 0 {
+			fs.EnumVar(p, name, *p, t.choices, t.usage)
+		} else {
+			fs.StringVar(p, name, *p, t.usage)
+		}
+	case *[]string:
+		fs.StringSliceVar(p, name, *p, t.usage)
+	case *[]int:
+		fs.IntSliceVar(p, name, *p, t.usage)
+	case *[]int64:
+		fs.Int64SliceVar(p, name, *p, t.usage)
+	case *[]uint:
+		fs.UintSliceVar(p, name, *p, t.usage)
+	case *[]uint64:
+		fs.Uint64SliceVar(p, name, *p, t.usage)
+	case *[]float64:
+		fs.Float64SliceVar(p, name, *p, t.usage)
+	case *[]time.Duration:
+		fs.DurationSliceVar(p, name, *p, t.usage)
+	case *map[string]string:
+		fs.StringMapVar(p, name, *p, t.usage)
+	default:
+		pfn := parserFor(fv.Type())
+		if pfn == nil {
+			return fmt.Errorf("unsupported flag type %s", fv.Type())
+		}
+		fs.Var(&reflectValue{fv, pfn}, name, t.usage)
+	}
+	if t.short != "" {
+		fs.SetShort(name, t.short)
+	}
+	if len(t.env) > 0 {
+		fs.SetEnv(name, t.env...)
+	}
+	if t.sep != "" {
+		fs.SetSeparator(name, t.sep)
+	}
+	if t.fold {
+		fs.SetCaseInsensitive(name)
+	}
+	if t.required {
+		fs.SetRequired(name)
+	}
+	if t.def != "" {
+		return fs.SetDefault(name, t.def)
+	}
+	return nil
+}
+
+// Returns a new Command as NewCommand, with a FlagSet of the Command tag bound
+// to the struct pointed to by v (see Bind).
+func BindCommand(group, tag, use string,
+	priority int,
+	escapes bool,
+	cfn CommandFunc,
+	v interface{}) (Command, error) {
+	fs := NewFlagSet(tag, ContinueOnError)
+	if err := Bind(fs, v); err != nil {
+		return nil, err
+	}
+	return NewCommand(group, tag, use, priority, escapes, cfn, fs), nil
+}
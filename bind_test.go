@@ -0,0 +1,136 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindDB struct {
+	Host    string `flip:"usage=database host"`
+	Port    int    `flip:"default=5432"`
+	private string
+}
+
+type bindLevel int
+
+type bindConfig struct {
+	Addr     string            `flip:"name=addr,short=a,usage=Address to listen on, host:port,env=FLIPTEST_ADDR,required"`
+	Verbose  bool              `flip:"short=v"`
+	Timeout  time.Duration     `flip:"usage=request timeout"`
+	Format   string            `flip:"choices=json|yaml,insensitive"`
+	Include  []string          `flip:"sep=comma"`
+	Labels   map[string]string `flip:"usage=labels"`
+	Level    bindLevel
+	Endpoint url.URL
+	TLSKey   string
+	DB       bindDB
+	Replica  *bindDB `flip:"prefix=ro."`
+	Skipped  string  `flip:"-"`
+}
+
+func TestBind(t *testing.T) {
+	cfg := &bindConfig{Timeout: time.Second, Format: "json"}
+	b := new(bytes.Buffer)
+	fs := NewFlagSet("bind", ContinueOnError)
+	fs.SetOut(b)
+	if err := Bind(fs, cfg); err != nil {
+		t.Fatalf("unexpected bind error: %s", err)
+	}
+	t.Setenv("FLIPTEST_ADDR", "localhost:80")
+	err := fs.Parse([]string{
+		"-v", "-timeout", "5s", "-format", "YAML", "-include", "a,b",
+		"-labels", "k=v", "-level", "2", "-endpoint", "https://example.com",
+		"-tls-key", "key", "-db-host", "db", "-ro.port", "6543",
+	})
+	if err != nil {
+		t.Fatalf("unexpected bound parse error: %s", err)
+	}
+	exp := bindConfig{
+		Addr: "localhost:80", Verbose: true, Timeout: 5 * time.Second, Format: "yaml",
+		Include: []string{"a", "b"}, Labels: map[string]string{"k": "v"}, Level: 2,
+		Endpoint: url.URL{Scheme: "https", Host: "example.com"}, TLSKey: "key",
+		DB: bindDB{Host: "db", Port: 5432}, Replica: &bindDB{Port: 6543},
+	}
+	if !reflect.DeepEqual(*cfg, exp) {
+		t.Errorf("bind error: expected %+v, got %+v", exp, *cfg)
+	}
+	if fs.Lookup("skipped") != nil || fs.Lookup("private") != nil || fs.Lookup("db-private") != nil {
+		t.Error("bind error: skipped or unexported field bound")
+	}
+
+	fs.Usage(b)
+	usg := b.String()
+	for _, u := range []string{
+		"-a, --addr string", "Address to listen on, host:port (env $FLIPTEST_ADDR) (required)",
+		"-v, --verbose", "-format json|yaml", "-level int", "-endpoint url", "-tls-key string",
+		"-db-port int", "(default 5432)", "-ro.host string",
+	} {
+		if !strings.Contains(usg, u) {
+			t.Errorf("bind usage error: expected %s in %s", u, usg)
+		}
+	}
+
+	if err := Bind(NewFlagSet("bad", ContinueOnError), bindConfig{}); err == nil {
+		t.Error("expected error binding a non pointer")
+	}
+	if err := Bind(NewFlagSet("bad", ContinueOnError), &struct{ C chan int }{}); err == nil {
+		t.Error("expected error binding an unsupported field")
+	}
+	if err := Bind(NewFlagSet("bad", ContinueOnError), &struct {
+		S string `flip:"nonsense"`
+	}{}); err == nil {
+		t.Error("expected error binding an unknown tag key")
+	}
+	if err := Bind(NewFlagSet("bad", ContinueOnError), &struct {
+		S string `flip:"name=addr,requried"`
+	}{}); err == nil || !strings.Contains(err.Error(), "unknown flip tag key") {
+		t.Errorf("expected unknown tag key error for a misspelled keyword, got %v", err)
+	}
+	tagged := &struct {
+		S string `flip:"default=a,b,usage=a list, of values"`
+	}{}
+	tfs := NewFlagSet("tagged", ContinueOnError)
+	if err := Bind(tfs, tagged); err != nil || tagged.S != "a,b" ||
+		tfs.Lookup("s").Message != "a list, of values" {
+		t.Errorf("expected comma continued default and usage, got %v, %v", tagged.S, err)
+	}
+	if err := Bind(NewFlagSet("bad", ContinueOnError), &struct{ *bindDB }{}); err == nil {
+		t.Error("expected error binding a nil embedded pointer to an unexported struct")
+	}
+	efs := NewFlagSet("embedded", ContinueOnError)
+	if err := Bind(efs, &struct{ *bindDB }{&bindDB{Port: 1}}); err != nil || efs.Lookup("port") == nil {
+		t.Errorf("expected embedded pointer to an unexported struct bound, got %v", err)
+	}
+}
+
+func TestBindCommand(t *testing.T) {
+	var ran string
+	cfg := &struct {
+		Name string `flip:"required"`
+	}{}
+	cmd, err := BindCommand("", "bound", "a bound command", 1, false,
+		func(c context.Context, a []string) (context.Context, ExitStatus) {
+			ran = cfg.Name
+			return c, ExitSuccess
+		},
+		cfg)
+	if err != nil {
+		t.Fatalf("unexpected bind command error: %s", err)
+	}
+	to := new(bytes.Buffer)
+	cmd.SetOut(to)
+	f := New("test")
+	f.SetOut(to)
+	f.SetGroup("bound", 1, cmd)
+	if res := f.Execute(context.Background(), []string{"test", "bound"}); res != -2 {
+		t.Errorf("bound command without required flag expected exit -2, got %d", res)
+	}
+	if res := f.Execute(context.Background(), []string{"test", "bound", "-name", "x"}); res != 0 || ran != "x" {
+		t.Errorf("bound command expected exit 0 with name x, got %d %q", res, ran)
+	}
+}
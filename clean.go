@@ -1,11 +1,22 @@
 package flip
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
 
 // A cleanup function taking a context.Context only.
 type Cleanup func(context.Context)
 
-type runCleanupFunc func(ExitStatus, context.Context) int
+// A cleanup function taking a context.Context and returning an error.
+type CleanupE func(context.Context) error
+
+type runCleanupFunc func(ExitStatus, context.Context) error
+
+// The error wrapped by errors of Cleanup functions exceeding a timeout.
+var ErrCleanupTimeout = errors.New("cleanup timed out")
 
 // An interface for post-command actions.
 type Cleaner interface {
@@ -13,35 +24,146 @@ type Cleaner interface {
 	RunCleanup(ExitStatus, context.Context) int
 }
 
+// An optional interface of a Cleaner for error returning, per Command,
+// ordered, and timed cleanup, implemented by Flippers of New.
+type CleanerE interface {
+	SetCleanupE(ExitStatus, ...CleanupE)
+	SetCommandCleanup(Command, ExitStatus, ...CleanupE)
+	SetCleanupLIFO(bool)
+	SetCleanupTimeout(time.Duration, time.Duration)
+	RunCleanupE(ExitStatus, context.Context) error
+}
+
+type commandCleanup struct {
+	cmd  Command
+	e    ExitStatus
+	cfns []CleanupE
+}
+
 type cleaner struct {
-	cfns map[ExitStatus][]Cleanup
+	cfns          map[ExitStatus][]CleanupE
+	commands      []*commandCleanup
+	lifo          bool
+	each, overall time.Duration
 }
 
 func newCleaner() *cleaner {
-	return &cleaner{make(map[ExitStatus][]Cleanup)}
+	return &cleaner{cfns: make(map[ExitStatus][]CleanupE)}
 }
 
 // Set the provided Cleanup functions to be run on the provided ExitStatus.
 func (c *cleaner) SetCleanup(e ExitStatus, cfns ...Cleanup) {
-	if c.cfns[e] == nil {
-		c.cfns[e] = make([]Cleanup, 0)
+	for _, cfn := range cfns {
+		cfn := cfn
+		c.SetCleanupE(e, func(ctx context.Context) error {
+			cfn(ctx)
+			return nil
+		})
 	}
+}
 
+// Set the provided error returning Cleanup functions to be run on the
+// provided ExitStatus.
+func (c *cleaner) SetCleanupE(e ExitStatus, cfns ...CleanupE) {
 	c.cfns[e] = append(c.cfns[e], cfns...)
 }
 
-// Given an ExitStatus and a context.Context, runs any associated Cleanup functions.
+// Set the provided error returning Cleanup functions to be run on the
+// provided ExitStatus (any status for ExitAny), only when the CommandFunc of
+// the provided Command has run. Command Cleanup functions run before any
+// others.
+func (c *cleaner) SetCommandCleanup(cmd Command, e ExitStatus, cfns ...CleanupE) {
+	c.commands = append(c.commands, &commandCleanup{cmd, e, cfns})
+}
+
+// Set Cleanup functions to run in reverse order of setting, as deferred
+// functions, for each ExitStatus and Command.
+func (c *cleaner) SetCleanupLIFO(lifo bool) {
+	c.lifo = lifo
+}
+
+// Set a timeout for each Cleanup function and for all Cleanup functions,
+// either zero for none. With a timeout, Cleanup functions receive a
+// context.Context ending at the timeout, and not with the execution
+// context.Context. A Cleanup function exceeding a timeout is abandoned,
+// returning an error wrapping ErrCleanupTimeout, and no Cleanup function runs
+// after the overall timeout.
+func (c *cleaner) SetCleanupTimeout(each, overall time.Duration) {
+	c.each, c.overall = each, overall
+}
+
+// Given an ExitStatus and a context.Context, runs any associated Cleanup
+// functions, returning the ExitStatus as an integer.
 func (c *cleaner) RunCleanup(e ExitStatus, ctx context.Context) int {
-	if cfns, ok := c.cfns[e]; ok {
-		for _, cfn := range cfns {
-			cfn(ctx)
+	c.RunCleanupE(e, ctx)
+	return int(e)
+}
+
+// Given an ExitStatus and a context.Context, runs any associated Cleanup
+// functions: those of Commands that have run, those of the ExitStatus, then
+// those of ExitAny, returning any errors joined.
+func (c *cleaner) RunCleanupE(e ExitStatus, ctx context.Context) error {
+	var cfns []CleanupE
+	for _, cc := range c.commands {
+		if r, ok := cc.cmd.(interface{ HasRun() bool }); ok && r.HasRun() && (cc.e == e || cc.e == ExitAny) {
+			cfns = append(cfns, c.order(cc.cfns)...)
 		}
 	}
-	if afns, ok := c.cfns[ExitAny]; ok {
-		for _, afn := range afns {
-			afn(ctx)
+	for _, cc := range c.commands {
+		if r, ok := cc.cmd.(interface{ clearRun() }); ok {
+			r.clearRun()
 		}
 	}
+	cfns = append(cfns, c.order(c.cfns[e])...)
+	if e != ExitAny {
+		cfns = append(cfns, c.order(c.cfns[ExitAny])...)
+	}
 
-	return int(e)
+	if c.each > 0 || c.overall > 0 {
+		ctx = context.WithoutCancel(ctx)
+	}
+	if c.overall > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.overall)
+		defer cancel()
+	}
+	var errs []error
+	for _, cfn := range cfns {
+		if c.overall > 0 && ctx.Err() != nil {
+			errs = append(errs, fmt.Errorf("%w: overall timeout %v", ErrCleanupTimeout, c.overall))
+			break
+		}
+		errs = append(errs, c.run(ctx, cfn))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *cleaner) order(cfns []CleanupE) []CleanupE {
+	if !c.lifo {
+		return cfns
+	}
+	ret := make([]CleanupE, len(cfns))
+	for i, cfn := range cfns {
+		ret[len(cfns)-1-i] = cfn
+	}
+	return ret
+}
+
+func (c *cleaner) run(ctx context.Context, cfn CleanupE) error {
+	if c.each <= 0 && c.overall <= 0 {
+		return cfn(ctx)
+	}
+	if c.each > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.each)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() { done <- cfn(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %v", ErrCleanupTimeout, ctx.Err())
+	}
 }
@@ -0,0 +1,109 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCleanup(t *testing.T) {
+	b := new(bytes.Buffer)
+	f := errorFlipper(b)
+	var order []string
+	note := func(s string) CleanupE {
+		return func(context.Context) error {
+			order = append(order, s)
+			return nil
+		}
+	}
+	errA, errB := errors.New("a failed"), errors.New("b failed")
+	f.SetCleanupE(ExitFailure, note("f1"), func(context.Context) error { return errA })
+	f.SetCleanup(ExitFailure, func(context.Context) { order = append(order, "f2") })
+	f.SetCleanupE(ExitAny, note("any"), func(context.Context) error { return errB })
+	fail := f.GetCommand("fail")[0]
+	f.SetCommandCleanup(fail, ExitFailure, note("c1"), note("c2"))
+	f.SetCommandCleanup(fail, ExitSuccess, note("never"))
+	for _, cmd := range f.GetGroup("run").Commands {
+		if cmd.Tag() == "run" {
+			f.SetCommandCleanup(cmd, ExitAny, note("run"))
+		}
+	}
+
+	r, _ := f.ExecuteE(context.Background(), []string{"test", "fail"})
+	if got := strings.Join(order, " "); got != "c1 c2 f1 f2 any" {
+		t.Errorf("unexpected cleanup order %q", got)
+	}
+	if !errors.Is(r.CleanupErr, errA) || !errors.Is(r.CleanupErr, errB) {
+		t.Errorf("expected joined cleanup errors, got %v", r.CleanupErr)
+	}
+
+	order = nil
+	var ce CleanerE = f
+	ce.SetCleanupLIFO(true)
+	f.ExecuteE(context.Background(), []string{"test", "fail"})
+	if got := strings.Join(order, " "); got != "c2 c1 f2 f1 any" {
+		t.Errorf("unexpected lifo cleanup order %q", got)
+	}
+
+	order = nil
+	r, _ = f.ExecuteE(context.Background(), []string{"test", "run"})
+	if got := strings.Join(order, " "); got != "run any" || !errors.Is(r.CleanupErr, errB) {
+		t.Errorf("unexpected run cleanups %q, %v", got, r.CleanupErr)
+	}
+	order = nil
+	f.ExecuteE(context.Background(), []string{"test", "run", "-nope"})
+	if got := strings.Join(order, " "); got != "any" {
+		t.Errorf("command cleanup ran without command %q", got)
+	}
+}
+
+func TestCleanupTimeout(t *testing.T) {
+	c := newCleaner()
+	var mu sync.Mutex
+	var ran []string
+	ranString := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return strings.Join(ran, " ")
+	}
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		ran = append(ran, "late")
+		mu.Unlock()
+		return nil
+	}
+	c.SetCleanupE(ExitFailure, hang, func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected cleanup deadline")
+		}
+		mu.Lock()
+		ran = append(ran, "next")
+		mu.Unlock()
+		return nil
+	})
+	c.SetCleanupTimeout(10*time.Millisecond, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := c.RunCleanupE(ExitFailure, ctx)
+	if !errors.Is(err, ErrCleanupTimeout) || ranString() != "next" {
+		t.Errorf("expected per cleanup timeout, got %v, %v", err, ran)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	ran = nil
+	mu.Unlock()
+	c.SetCleanupTimeout(0, 15*time.Millisecond)
+	err = c.RunCleanupE(ExitFailure, context.Background())
+	if !errors.Is(err, ErrCleanupTimeout) || ranString() != "" {
+		t.Errorf("expected overall timeout, got %v, %v", err, ranString())
+	}
+	time.Sleep(100 * time.Millisecond)
+}
@@ -0,0 +1,365 @@
+package flip
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	completeNone  = ":none"
+	completeFiles = ":files"
+	completeDirs  = ":dirs"
+)
+
+// A type describing how the value of a flag is completed.
+type Completer struct {
+	directive string
+	fn        func(string) []string
+}
+
+// Returns a Completer of file names, completed by the shell.
+func CompleteFiles() *Completer {
+	return &Completer{completeFiles, nil}
+}
+
+// Returns a Completer of directory names, completed by the shell.
+func CompleteDirs() *Completer {
+	return &Completer{completeDirs, nil}
+}
+
+// Returns a Completer of the provided choices.
+func CompleteChoices(choices ...string) *Completer {
+	return &Completer{completeNone, func(string) []string { return choices }}
+}
+
+// Returns a Completer of the candidates returned by the provided function,
+// given the partial value being completed.
+func CompleteFunc(fn func(string) []string) *Completer {
+	return &Completer{completeNone, fn}
+}
+
+// Returns completion candidates for the provided partial value, and a
+// directive line for the shell.
+func (c *Completer) Complete(partial string) ([]string, string) {
+	if c.fn == nil {
+		return nil, c.directive
+	}
+	return c.fn(partial), c.directive
+}
+
+// Sets the provided Completer for values of the previously defined flag of the
+// provided name. This will panic for an undefined flag.
+func (f *FlagSet) SetCompleter(name string, c *Completer) {
+	if _, ok := f.formal[name]; !ok {
+		msg := fmt.Sprintf("%s flag not defined for completer: %s", f.name, name)
+		fmt.Fprintln(f.Out(), msg)
+		panic(msg)
+	}
+	if f.completers == nil {
+		f.completers = make(map[string]*Completer)
+	}
+	f.completers[name] = c
+}
+
+// Returns the Completer of the flag of the provided name or short name: any
+// Completer set, the choices of an enum flag, true or false for a boolean
+// flag, or nil.
+func (f *FlagSet) Completer(name string) *Completer {
+	flag := f.Lookup(name)
+	if flag == nil {
+		return nil
+	}
+	if c, ok := f.completers[flag.Name]; ok {
+		return c
+	}
+	switch v := flag.Value.(type) {
+	case Chooser:
+		return CompleteChoices(v.Choices()...)
+	case boolFlag:
+		if v.IsBoolFlag() {
+			return CompleteChoices("true", "false")
+		}
+	}
+	return nil
+}
+
+func flagNeedsValue(fs Flagger, arg string) (*Flag, bool) {
+	if len(arg) < 2 || arg[0] != '-' || strings.Contains(arg, "=") {
+		return nil, false
+	}
+	flag := fs.Lookup(strings.TrimLeft(arg, "-"))
+	if flag == nil {
+		return nil, false
+	}
+	if bv, ok := flag.Value.(boolFlag); ok && bv.IsBoolFlag() {
+		return flag, false
+	}
+	return flag, true
+}
+
+func flagCompleter(fs Flagger, name string) *Completer {
+	if c, ok := fs.(interface{ Completer(string) *Completer }); ok {
+		return c.Completer(name)
+	}
+	return nil
+}
+
+func filterPrefix(cs []string, prefix string) []string {
+	var ret []string
+	for _, c := range cs {
+		if strings.HasPrefix(c, prefix) {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// Returns completion candidates and a directive for the final, possibly
+// partial, word of the provided command line words, resolving commands as in
+// execution.
+func complete(cm Commander, words []string) ([]string, string) {
+	if len(words) == 0 {
+		return nil, completeNone
+	}
+	fn := isCommand(cm)
+	cur := words[len(words)-1]
+	var at *queueCmd
+	var esc Command
+	var value *Flag
+	for _, w := range words[:len(words)-1] {
+		if value != nil {
+			value = nil
+			continue
+		}
+		if at != nil {
+			if flag, needs := flagNeedsValue(at.Command, w); flag != nil {
+				if needs {
+					value = flag
+				}
+				continue
+			}
+		}
+		qc := fn(at, w)
+		if qc == nil || (esc != nil && !descends(qc.Command, esc)) {
+			continue
+		}
+		at = qc
+		if qc.escapes {
+			esc = qc.Command
+		}
+	}
+
+	var cs []string
+	directive := completeNone
+	switch {
+	case value != nil:
+		if c := flagCompleter(at.Command, value.Name); c != nil {
+			cs, directive = c.Complete(cur)
+		}
+	case at != nil && strings.HasPrefix(cur, "-") && strings.Contains(cur, "="):
+		i := strings.Index(cur, "=")
+		if c := flagCompleter(at.Command, strings.TrimLeft(cur[:i], "-")); c != nil {
+			var vs []string
+			vs, directive = c.Complete(cur[i+1:])
+			for _, v := range vs {
+				cs = append(cs, cur[:i+1]+v)
+			}
+		}
+	case at != nil && strings.HasPrefix(cur, "-"):
+		at.Command.VisitAll(func(flag *Flag) {
+			cs = append(cs, "-"+flag.Name)
+			if flag.Short != "" {
+				cs = append(cs, "-"+flag.Short)
+			}
+		})
+	default:
+		if at != nil {
+			for p := at.Command; p != nil; p = p.Parent() {
+				for _, ch := range p.Children() {
+					cs = append(cs, ch.Tag())
+				}
+				if p == esc {
+					break
+				}
+			}
+		}
+		if esc == nil {
+			for _, g := range cm.Groups().Has {
+				if g.Hidden {
+					continue
+				}
+				for _, cmd := range g.Commands {
+					cs = append(cs, cmd.Tag())
+				}
+			}
+		}
+	}
+	cs = filterPrefix(cs, cur)
+	sort.Strings(cs)
+	return cs, directive
+}
+
+func writeCompletion(o io.Writer, cs []string, directive string) {
+	for _, c := range cs {
+		fmt.Fprintln(o, c)
+	}
+	fmt.Fprintln(o, directive)
+}
+
+func shellName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, filepath.Base(name))
+}
+
+const bashCompletion = `# bash completion for %[1]s
+_%[2]s_complete() {
+    local cur words cword
+    if declare -F _get_comp_words_by_ref >/dev/null; then
+        _get_comp_words_by_ref -n = cur words cword
+    else
+        local line="${COMP_LINE:0:COMP_POINT}"
+        read -ra words <<< "$line"
+        [[ -z "$line" || "$line" == *[[:space:]] ]] && words+=("")
+        cword=$((${#words[@]}-1))
+        cur="${words[cword]}"
+    fi
+    local -a lines
+    mapfile -t lines < <("${words[0]}" __complete -- "${words[@]:0:$((cword+1))}" 2>/dev/null)
+    local directive="${lines[-1]}"
+    unset 'lines[-1]'
+    case "$directive" in
+        :files) COMPREPLY=( $(compgen -f -- "${cur#*=}") ) ;;
+        :dirs) COMPREPLY=( $(compgen -d -- "${cur#*=}") ) ;;
+        *) COMPREPLY=( "${lines[@]}" ) ;;
+    esac
+    if [[ "$cur" == *=* && "$COMP_WORDBREAKS" == *=* ]]; then
+        local prefix="${cur%%"${cur##*=}"}"
+        COMPREPLY=( "${COMPREPLY[@]#"$prefix"}" )
+    fi
+}
+complete -F _%[2]s_complete %[1]s
+`
+
+const zshCompletion = `#compdef %[1]s
+_%[2]s_complete() {
+    local -a lines
+    lines=("${(@f)$(${words[1]} __complete -- "${(@)words[1,$CURRENT]}" 2>/dev/null)}")
+    local directive="${lines[-1]}"
+    lines=("${(@)lines[1,-2]}")
+    case "$directive" in
+        :files) _files ;;
+        :dirs) _files -/ ;;
+        *) compadd -- "${lines[@]}" ;;
+    esac
+}
+compdef _%[2]s_complete %[1]s
+`
+
+const fishCompletion = `# fish completion for %[1]s
+function __%[2]s_complete
+    set -l tokens (commandline -opc)
+    set -l lines ($tokens[1] __complete -- $tokens (commandline -ct | string collect) 2>/dev/null)
+    set -l directive $lines[-1]
+    set -e lines[-1]
+    switch $directive
+        case :files
+            __fish_complete_path (commandline -ct)
+        case :dirs
+            __fish_complete_directories (commandline -ct)
+        case '*'
+            printf '%%s\n' $lines
+    end
+end
+complete -c %[1]s -f -a '(__%[2]s_complete)'
+`
+
+// Writes a completion script for the provided shell (bash, zsh, or fish) and
+// program name to the provided io.Writer. Scripts call the hidden __complete
+// command of the program, so completions remain in sync with the program.
+func CompletionScript(o io.Writer, shell, name string) error {
+	var script string
+	switch shell {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+	default:
+		return fmt.Errorf("unsupported completion shell %q, must be one of bash, zsh, fish", shell)
+	}
+	fmt.Fprintf(o, script, filepath.Base(name), shellName(name))
+	return nil
+}
+
+type completion struct {
+	f     *flipper
+	shell string
+}
+
+func completionFlag(c *completion) *FlagSet {
+	fs := NewFlagSet("completion", ContinueOnError)
+	fs.EnumVar(&c.shell, "shell", "", []string{"bash", "zsh", "fish"}, "The shell to write a completion script for.")
+	return fs
+}
+
+func (c *completion) command() Command {
+	return NewCommand(
+		"",
+		"completion",
+		`Writes a bash, zsh, or fish completion script, e.g. completion bash.`,
+		1,
+		true,
+		func(ctx context.Context, a []string) (context.Context, ExitStatus) {
+			shell := c.shell
+			for _, v := range a {
+				if !strings.HasPrefix(v, "-") {
+					shell = v
+				}
+			}
+			c.shell = ""
+			if err := CompletionScript(c.f.Out(), shell, c.f.name); err != nil {
+				fmt.Fprintln(c.f.Out(), err)
+				return ctx, ExitUsageError
+			}
+			return ctx, ExitSuccess
+		},
+		completionFlag(c),
+	)
+}
+
+func (c *completion) complete() Command {
+	return NewCommand(
+		"",
+		"__complete",
+		`Writes completion candidates for the provided command line words.`,
+		1,
+		true,
+		func(ctx context.Context, a []string) (context.Context, ExitStatus) {
+			if len(a) > 0 && a[0] == "--" {
+				a = a[1:]
+			}
+			cs, directive := complete(c.f.Commander, a)
+			writeCompletion(c.f.Out(), cs, directive)
+			return ctx, ExitSuccess
+		},
+		NewFlagSet("__complete", ContinueOnError),
+	)
+}
+
+func (f *flipper) addCompletion() *flipper {
+	c := &completion{f, ""}
+	f.SetGroup("completion", -1000, c.command())
+	f.SetGroup("__complete", -1000, c.complete())
+	f.GetGroup("__complete").Hidden = true
+	return f
+}
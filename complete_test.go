@@ -0,0 +1,91 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func completeFlipper(to *bytes.Buffer) *flipper {
+	fs := NewFlagSet("push", ContinueOnError)
+	fs.String("name", "", "a name")
+	fs.EnumVar(new(string), "format", "json", []string{"json", "yaml"}, "An enum flag")
+	fs.String("file", "", "A file flag")
+	fs.SetCompleter("file", CompleteFiles())
+	fs.String("color", "", "A callback flag")
+	fs.SetShort("color", "c")
+	fs.SetCompleter("color", CompleteFunc(func(s string) []string { return []string{"red", "green", "blue"} }))
+	var ran []string
+	remote := subCmdSet(&ran, to)
+	remote.SetChild(NewCommand("", "push", "push command", 1, false, nil, fs))
+	f := New("test")
+	f.SetOut(to)
+	f.AddBuiltIn("help").
+		AddBuiltIn("completion").
+		SetGroup("remote", 1, remote)
+	return f
+}
+
+var completeExpect = []struct {
+	words     []string
+	cs        []string
+	directive string
+}{
+	{[]string{"test", ""}, []string{"completion", "help", "remote"}, ":none"},
+	{[]string{"test", "re"}, []string{"remote"}, ":none"},
+	{[]string{"test", "add"}, nil, ":none"},
+	{[]string{"test", "remote", ""}, []string{"add", "completion", "help", "list", "push", "remote", "show"}, ":none"},
+	{[]string{"test", "remote", "push", "-"}, []string{"-c", "-color", "-file", "-format", "-name"}, ":none"},
+	{[]string{"test", "remote", "push", "-format", ""}, []string{"json", "yaml"}, ":none"},
+	{[]string{"test", "remote", "push", "-format=y"}, []string{"-format=yaml"}, ":none"},
+	{[]string{"test", "remote", "push", "-file", "x"}, nil, ":files"},
+	{[]string{"test", "remote", "push", "-c", "g"}, []string{"green"}, ":none"},
+	{[]string{"test", "remote", "push", "-name", "remote", "l"}, []string{"list"}, ":none"},
+	{[]string{"test", "remote", "show", ""}, []string{"detail"}, ":none"},
+	{[]string{"test", "help", ""}, nil, ":none"},
+}
+
+func TestComplete(t *testing.T) {
+	for _, x := range completeExpect {
+		f := completeFlipper(new(bytes.Buffer))
+		cs, directive := complete(f.Commander, x.words)
+		if !reflect.DeepEqual(cs, x.cs) || directive != x.directive {
+			t.Errorf("%v: expected %v %s, got %v %s", x.words, x.cs, x.directive, cs, directive)
+		}
+	}
+
+	to := new(bytes.Buffer)
+	f := completeFlipper(to)
+	if res := f.Execute(context.Background(), []string{"test", "__complete", "--", "test", "remote", "push", "-format", ""}); res != 0 {
+		t.Errorf("__complete expected exit 0, got %d", res)
+	}
+	if out := to.String(); out != "json\nyaml\n:none\n" {
+		t.Errorf("__complete unexpected output %q", out)
+	}
+
+	to.Reset()
+	f.Execute(context.Background(), []string{"test", "help"})
+	if strings.Contains(to.String(), "__complete") {
+		t.Errorf("hidden __complete command in help:\n\n%s", to.String())
+	}
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		to.Reset()
+		if res := f.Execute(context.Background(), []string{"test", "completion", shell}); res != 0 {
+			t.Errorf("completion %s expected exit 0, got %d", shell, res)
+		}
+		if !strings.Contains(to.String(), "__complete") || !strings.Contains(to.String(), "_test_complete") {
+			t.Errorf("completion %s unexpected script:\n\n%s", shell, to.String())
+		}
+	}
+	to.Reset()
+	if res := f.Execute(context.Background(), []string{"test", "completion", "-shell", "zsh"}); res != 0 || !strings.Contains(to.String(), "#compdef test") {
+		t.Errorf("completion -shell zsh unexpected result %d:\n\n%s", res, to.String())
+	}
+	to.Reset()
+	if res := f.Execute(context.Background(), []string{"test", "completion", "csh"}); res != -2 {
+		t.Errorf("completion of unsupported shell expected exit -2, got %d", res)
+	}
+}
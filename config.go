@@ -0,0 +1,377 @@
+package flip
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A Config holds flag values keyed by section and flag name. A section is a
+// command tag or space delimited command path (e.g. "remote add"); the empty
+// section holds values for a flag name in any command.
+type Config map[string]map[string][]string
+
+// Returns a new empty Config.
+func NewConfig() Config {
+	return make(Config)
+}
+
+// Sets the values of the provided key in the provided section, replacing any
+// existing values.
+func (c Config) Set(section, key string, values ...string) {
+	if c[section] == nil {
+		c[section] = make(map[string][]string)
+	}
+	c[section][key] = values
+}
+
+// Returns the values of the provided key in the provided section, and a boolean
+// indicating if the key was found.
+func (c Config) Get(section, key string) ([]string, bool) {
+	v, ok := c[section][key]
+	return v, ok
+}
+
+// Merges the provided Config into this Config, the provided values overriding
+// any existing values.
+func (c Config) Merge(o Config) {
+	for section, kv := range o {
+		for k, v := range kv {
+			c.Set(section, k, v...)
+		}
+	}
+}
+
+// Sets every key of the provided section into the provided StringContain,
+// multiple values being comma delimited.
+func (c Config) Contain(section string, d StringContain) {
+	for k, v := range c[section] {
+		d.SetString(k, strings.Join(v, ","))
+	}
+}
+
+// Applies Config values as defaults to the provided Flagger of a command with
+// the provided tag and path, values of the command path taking precedence
+// over the command tag, and the command tag over the empty section. Returns an
+// error for a value of a flag of a Flagger that is not a Defaulter.
+func (c Config) Apply(tag, path string, fs Flagger) error {
+	sections := []string{path, tag, ""}
+	d, _ := fs.(Defaulter)
+	var err error
+	fs.VisitAll(func(flag *Flag) {
+		if err != nil {
+			return
+		}
+		for _, s := range sections {
+			if v, ok := c.Get(s, flag.Name); ok {
+				if d == nil {
+					err = fmt.Errorf("cannot set configuration value %q for flag -%s", v, flag.Name)
+					fmt.Fprintln(fs.Out(), err)
+				} else if err = d.SetDefault(flag.Name, v...); err != nil {
+					err = fmt.Errorf("invalid configuration value %q for flag -%s: %v", v, flag.Name, err)
+					fmt.Fprintln(fs.Out(), err)
+				}
+				return
+			}
+		}
+	})
+	return err
+}
+
+func applyConfig(c Config, cmd Command) error {
+	if err := c.Apply(cmd.Tag(), cmd.Path(), cmd); err != nil {
+		return err
+	}
+	for _, ch := range cmd.Children() {
+		if err := applyConfig(c, ch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns a Config parsed from the provided io.Reader of JSON. Top level
+// objects are sections keyed by command tag, nested objects being sections of
+// nested commands, and any other top level values belonging to the empty
+// section. Arrays provide multiple values for a key.
+func ParseJSON(r io.Reader) (Config, error) {
+	var m map[string]interface{}
+	d := json.NewDecoder(r)
+	d.UseNumber()
+	if err := d.Decode(&m); err != nil {
+		return nil, err
+	}
+	c := NewConfig()
+	return c, jsonSection(c, "", m)
+}
+
+func jsonSection(c Config, section string, m map[string]interface{}) error {
+	for k, v := range m {
+		if sub, ok := v.(map[string]interface{}); ok {
+			if err := jsonSection(c, strings.TrimSpace(section+" "+k), sub); err != nil {
+				return err
+			}
+			continue
+		}
+		vs, err := jsonValues(v)
+		if err != nil {
+			return fmt.Errorf("config key %s: %v", k, err)
+		}
+		if vs != nil {
+			c.Set(section, k, vs...)
+		}
+	}
+	return nil
+}
+
+func jsonValues(v interface{}) ([]string, error) {
+	switch vv := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{vv}, nil
+	case json.Number:
+		return []string{vv.String()}, nil
+	case bool:
+		return []string{strconv.FormatBool(vv)}, nil
+	case []interface{}:
+		ret := make([]string, 0, len(vv))
+		for _, i := range vv {
+			s, err := jsonValues(i)
+			if err != nil || len(s) != 1 {
+				return nil, fmt.Errorf("unsupported array value %v", i)
+			}
+			ret = append(ret, s[0])
+		}
+		return ret, nil
+	}
+	return nil, fmt.Errorf("unsupported value %v", v)
+}
+
+// Returns a Config parsed from the provided io.Reader of INI or TOML style
+// text, of which a plain key=value file is a subset. A [section] header names
+// a command tag or path, dotted names (e.g. [remote.add]) being read as
+// command paths. Keys before any header belong to the empty section. Values
+// may be quoted, and a bracketed [a, b] list provides multiple values. Lines
+// beginning with '#' or ';' are comments, as is the remainder of a line from
+// an unquoted '#' following whitespace.
+func ParseINI(r io.Reader) (Config, error) {
+	c := NewConfig()
+	section := ""
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "", line[0] == '#', line[0] == ';':
+			continue
+		case line[0] == '[':
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("config line %d: bad section syntax: %s", n, line)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if uq, ok := unquoteConfig(name); ok {
+				section = uq
+			} else {
+				section = strings.Replace(name, ".", " ", -1)
+			}
+			continue
+		}
+		i := strings.IndexByte(line, '=')
+		if i < 1 {
+			return nil, fmt.Errorf("config line %d: bad key value syntax: %s", n, line)
+		}
+		key := strings.TrimSpace(line[:i])
+		if uq, ok := unquoteConfig(key); ok {
+			key = uq
+		}
+		vs, err := iniValues(strings.TrimSpace(line[i+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("config line %d: %v", n, err)
+		}
+		c.Set(section, key, vs...)
+	}
+	return c, s.Err()
+}
+
+func iniValues(v string) ([]string, error) {
+	v = stripComment(v)
+	if strings.HasPrefix(v, "[") {
+		if !strings.HasSuffix(v, "]") {
+			return nil, fmt.Errorf("bad list syntax: %s", v)
+		}
+		var ret []string
+		for _, i := range strings.Split(v[1:len(v)-1], ",") {
+			i = strings.TrimSpace(i)
+			if i == "" {
+				continue
+			}
+			if uq, ok := unquoteConfig(i); ok {
+				i = uq
+			}
+			ret = append(ret, i)
+		}
+		return ret, nil
+	}
+	if uq, ok := unquoteConfig(v); ok {
+		return []string{uq}, nil
+	}
+	return []string{v}, nil
+}
+
+// removes a trailing comment, a '#' following whitespace outside of quotes
+func stripComment(v string) string {
+	var quote byte
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"', c == '\'':
+			quote = c
+		case c == '#' && i > 0 && (v[i-1] == ' ' || v[i-1] == '\t'):
+			return strings.TrimSpace(v[:i])
+		}
+	}
+	return v
+}
+
+func unquoteConfig(s string) (string, bool) {
+	if len(s) < 2 {
+		return s, false
+	}
+	switch {
+	case s[0] == '"' && s[len(s)-1] == '"':
+		if uq, err := strconv.Unquote(s); err == nil {
+			return uq, true
+		}
+	case s[0] == '\'' && s[len(s)-1] == '\'':
+		return s[1 : len(s)-1], true
+	}
+	return s, false
+}
+
+// Returns a Config read from the file at the provided path, parsed as JSON for
+// a .json extension or content beginning with '{', and as INI otherwise.
+func ReadConfig(path string) (Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if filepath.Ext(path) == ".json" || bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		c, err = ParseJSON(bytes.NewReader(b))
+	} else {
+		c, err = ParseINI(bytes.NewReader(b))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return c, nil
+}
+
+// Returns the standard configuration file locations for the provided name, in
+// order of increasing precedence: config.json, config.toml, config.ini and
+// config within $XDG_CONFIG_HOME/<name>/ (defaulting to ~/.config), then
+// ./.<name>rc in the working directory.
+func ConfigPaths(name string) []string {
+	var ret []string
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		if h, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(h, ".config")
+		}
+	}
+	if dir != "" {
+		for _, f := range []string{"config.json", "config.toml", "config.ini", "config"} {
+			ret = append(ret, filepath.Join(dir, name, f))
+		}
+	}
+	return append(ret, fmt.Sprintf(".%src", name))
+}
+
+// An optional interface of a Flipper for loading configuration applied to
+// command flags as defaults, before command line arguments are parsed,
+// implemented by Flippers of New.
+type Configurer interface {
+	Config() Config
+	SetConfig(string, string, ...string)
+	LoadConfig(...string) error
+	SearchConfig() error
+}
+
+type configurer struct {
+	name    string
+	cm      Commander
+	config  Config
+	applied bool
+}
+
+func newConfigurer(name string, cm Commander) *configurer {
+	return &configurer{name, cm, NewConfig(), false}
+}
+
+// Returns the Config loaded so far. Values set directly are not applied, see
+// SetConfig.
+func (c *configurer) Config() Config {
+	return c.config
+}
+
+// Sets the values of the provided key in the provided section of the Config,
+// applying the Config on the next execution.
+func (c *configurer) SetConfig(section, key string, values ...string) {
+	c.config.Set(section, key, values...)
+	c.applied = false
+}
+
+// Loads the files at the provided paths in order, values of later files
+// overriding values of earlier files.
+func (c *configurer) LoadConfig(paths ...string) error {
+	for _, p := range paths {
+		l, err := ReadConfig(p)
+		if err != nil {
+			return err
+		}
+		c.config.Merge(l)
+	}
+	c.applied = false
+	return nil
+}
+
+// Loads any existing files of the standard configuration locations for the
+// Flipper name (see ConfigPaths).
+func (c *configurer) SearchConfig() error {
+	for _, p := range ConfigPaths(c.name) {
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		if err := c.LoadConfig(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *configurer) apply() error {
+	if c.applied || len(c.config) == 0 {
+		return nil
+	}
+	for _, g := range c.cm.Groups().Has {
+		for _, cmd := range g.Commands {
+			if err := applyConfig(c.config, cmd); err != nil {
+				return err
+			}
+		}
+	}
+	c.applied = true
+	return nil
+}
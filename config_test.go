@@ -0,0 +1,180 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/1xch/lal"
+)
+
+var configExpect = []struct {
+	parse func(string) (Config, error)
+	in    string
+	exp   Config
+}{
+	{
+		func(s string) (Config, error) { return ParseJSON(strings.NewReader(s)) },
+		`{"value": "top", "one-A": {"b1": true, "n": 10}, "remote": {"add": {"name": ["x", "y"]}}}`,
+		Config{
+			"":           {"value": {"top"}},
+			"one-A":      {"b1": {"true"}, "n": {"10"}},
+			"remote add": {"name": {"x", "y"}},
+		},
+	},
+	{
+		func(s string) (Config, error) { return ParseINI(strings.NewReader(s)) },
+		"# comment\nvalue = top\n[one-A]\nb1=true\n; comment\nn = 10 # inline\n[remote.add]\nname = [\"x\", 'y']\n[\"a.b\"]\nkey = \"quoted # value\"",
+		Config{
+			"":           {"value": {"top"}},
+			"one-A":      {"b1": {"true"}, "n": {"10"}},
+			"remote add": {"name": {"x", "y"}},
+			"a.b":        {"key": {"quoted # value"}},
+		},
+	},
+	{
+		func(s string) (Config, error) { return ParseINI(strings.NewReader(s)) },
+		"k = \"x\" # c\nl = [a, b] # c\nm = 'y #z' # c\n",
+		Config{
+			"": {"k": {"x"}, "l": {"a", "b"}, "m": {"y #z"}},
+		},
+	},
+}
+
+func TestParseConfig(t *testing.T) {
+	for _, x := range configExpect {
+		c, err := x.parse(x.in)
+		if err != nil {
+			t.Errorf("unexpected config parse error: %s", err)
+		}
+		if !reflect.DeepEqual(c, x.exp) {
+			t.Errorf("config parse expected %v, got %v", x.exp, c)
+		}
+	}
+	if _, err := ParseINI(strings.NewReader("[section\n")); err == nil {
+		t.Error("expected error for bad section syntax")
+	}
+	if _, err := ParseINI(strings.NewReader("novalue\n")); err == nil {
+		t.Error("expected error for bad key value syntax")
+	}
+
+	fs := NewFlagSet("apply", ContinueOnError)
+	fs.SetOut(new(bytes.Buffer))
+	fs.String("str", "", "A string flag")
+	c := Config{"": {"str": {"x"}}}
+	if err := c.Apply("apply", "apply", struct{ Flagger }{fs}); err == nil {
+		t.Error("expected error applying to a Flagger that is not a Defaulter")
+	}
+	if err := c.Apply("apply", "apply", fs); err != nil || fs.Lookup("str").Value.String() != "x" {
+		t.Errorf("unexpected apply result %v", err)
+	}
+}
+
+func TestConfigFlipper(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flipconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	os.MkdirAll(filepath.Join(dir, "test"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "test", "config.json"), []byte(`{"one-B": {"str": "json", "b2": true}, "str": "global"}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "local.conf"), []byte("[one-B]\nstr = ini\n[one-A]\nsv = contained\n"), 0644)
+
+	var ranWith, envWith string
+	_, itm := lal.NewItem("test_config")
+	tf := &tflags{}
+	to := new(bytes.Buffer)
+	fsA := testFlagSet("one-A", tf, to)
+	fsA.StringContainVar(itm, "sv", "svKey", "", "A contain backed string flag")
+	fsB := testFlagSet("one-B", tf, to)
+	fsB.String("str", "default", "A string flag")
+	fsB.String("env", "default", "A string flag")
+	fsB.SetEnv("env", "FLIPTEST_CONFIG_ENV")
+	t.Setenv("FLIPTEST_CONFIG_ENV", "env")
+
+	f := New("test")
+	f.SetOut(to)
+	f.SetGroup("one", 1,
+		NewCommand("", "one-A", "", 1, false,
+			func(c context.Context, s []string) (context.Context, ExitStatus) { return c, ExitNo },
+			fsA),
+		NewCommand("", "one-B", "", 2, false,
+			func(c context.Context, s []string) (context.Context, ExitStatus) {
+				ranWith = fsB.Lookup("str").Value.String()
+				envWith = fsB.Lookup("env").Value.String()
+				return c, ExitSuccess
+			},
+			fsB),
+	)
+	if err := f.SearchConfig(); err != nil {
+		t.Fatalf("unexpected config search error: %s", err)
+	}
+	if err := f.LoadConfig(filepath.Join(dir, "local.conf")); err != nil {
+		t.Fatalf("unexpected config load error: %s", err)
+	}
+	f.SetConfig("", "env", "config")
+
+	if res := f.Execute(context.Background(), []string{"test", "one-A", "one-B"}); res != 0 {
+		t.Errorf("expected exit 0, got %d", res)
+	}
+	if ranWith != "ini" || envWith != "env" || !tf.b2 || itm.ToString("svKey") != "contained" {
+		t.Errorf("config not applied: str %q, env %q, b2 %t, sv %q", ranWith, envWith, tf.b2, itm.ToString("svKey"))
+	}
+
+	ranWith = ""
+	if res := f.Execute(context.Background(), []string{"test", "one-B", "-str", "argv"}); res != 0 || ranWith != "argv" {
+		t.Errorf("expected command line value to override configuration, got %q", ranWith)
+	}
+
+	fsB.SetDefault("str", "mine")
+	if v, ok := f.Config().Get("one-B", "str"); !ok || v[0] != "ini" {
+		t.Errorf("unexpected config value %v", v)
+	}
+	if f.Execute(context.Background(), []string{"test", "one-B"}); ranWith != "mine" {
+		t.Errorf("expected reading the config not to reapply it, got %q", ranWith)
+	}
+	f.SetConfig("one-B", "str", "set")
+	if f.Execute(context.Background(), []string{"test", "one-B"}); ranWith != "set" {
+		t.Errorf("expected a set config value applied, got %q", ranWith)
+	}
+
+	if err := f.LoadConfig(filepath.Join(dir, "nonexistent")); err == nil {
+		t.Error("expected error loading nonexistent configuration")
+	}
+}
+
+func TestConfigRequired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "required.conf")
+	if err := os.WriteFile(path, []byte("[run]\nname = config\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var name string
+	to := new(bytes.Buffer)
+	fs := NewFlagSet("run", ContinueOnError)
+	fs.SetOut(to)
+	fs.StringVar(&name, "name", "", "A required flag")
+	fs.String("other", "", "A flag")
+	fs.SetRequired("name")
+	fs.SetRequires("other", "name")
+	f := New("test")
+	f.SetOut(to)
+	f.SetGroup("run", 1, NewCommand("", "run", "", 1, false,
+		func(c context.Context, s []string) (context.Context, ExitStatus) { return c, ExitSuccess },
+		fs))
+
+	if res := f.Execute(context.Background(), []string{"test", "run", "-other", "x"}); res != -2 {
+		t.Errorf("expected exit -2 without the required flag, got %d", res)
+	}
+	if err := f.LoadConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	if res := f.Execute(context.Background(), []string{"test", "run", "-other", "x"}); res != 0 || name != "config" {
+		t.Errorf("expected the configured required flag to satisfy constraints, got %d %q", res, name)
+	}
+}
@@ -0,0 +1,201 @@
+package flip
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// An integer type representing the kind of a flag Constraint.
+type ConstraintKind int
+
+const (
+	Required    ConstraintKind = iota // every flag must be set
+	Exclusive                         // no more than one flag may be set
+	OneRequired                       // at least one flag must be set
+	Requires                          // the first flag, when set, requires every other flag be set
+)
+
+// Returns a string description of the ConstraintKind.
+func (k ConstraintKind) String() string {
+	switch k {
+	case Required:
+		return "required"
+	case Exclusive:
+		return "mutually exclusive"
+	case OneRequired:
+		return "at least one required"
+	case Requires:
+		return "requires"
+	}
+	return "unknown"
+}
+
+// A type representing a constraint on flags of a *FlagSet, enforced when
+// parsing. A flag is considered set when provided on the command line or
+// from the environment to the parse. A flag with a default from SetDefault, e.g. from a
+// Config, is also considered provided for Required and OneRequired, and as a
+// flag required by Requires.
+type Constraint struct {
+	Kind  ConstraintKind
+	Flags []string
+}
+
+func (c *Constraint) names(fs []string) string {
+	return "-" + strings.Join(fs, ", -")
+}
+
+// Returns a string usage description of the Constraint.
+func (c *Constraint) String() string {
+	switch c.Kind {
+	case Requires:
+		return fmt.Sprintf("-%s requires %s", c.Flags[0], c.names(c.Flags[1:]))
+	case Required:
+		return fmt.Sprintf("%s required", c.names(c.Flags))
+	}
+	return fmt.Sprintf("[-%s] %s", strings.Join(c.Flags, " | -"), c.Kind)
+}
+
+// checks the Constraint given functions reporting a flag set, and a flag set
+// or with a default satisfying required flags
+func (c *Constraint) check(set, provided func(string) bool) *ConstraintError {
+	var on, off []string
+	for _, n := range c.Flags {
+		if set(n) {
+			on = append(on, n)
+		}
+		if !provided(n) {
+			off = append(off, n)
+		}
+	}
+	switch c.Kind {
+	case Required:
+		if len(off) > 0 {
+			return &ConstraintError{c, off}
+		}
+	case Exclusive:
+		if len(on) > 1 {
+			return &ConstraintError{c, on}
+		}
+	case OneRequired:
+		if len(off) == len(c.Flags) {
+			return &ConstraintError{c, off}
+		}
+	case Requires:
+		if set(c.Flags[0]) && len(off) > 0 {
+			return &ConstraintError{c, off}
+		}
+	}
+	return nil
+}
+
+// An error type returned from parsing when a Constraint is violated,
+// containing the Constraint and the names of the offending flags.
+type ConstraintError struct {
+	*Constraint
+	Offending []string
+}
+
+// Returns the ConstraintError as a string.
+func (e *ConstraintError) Error() string {
+	switch e.Kind {
+	case Required:
+		return fmt.Sprintf("required flag not provided: %s", e.names(e.Offending))
+	case Exclusive:
+		return fmt.Sprintf("flags are mutually exclusive: %s", e.names(e.Offending))
+	case OneRequired:
+		return fmt.Sprintf("at least one flag required: %s", e.names(e.Offending))
+	case Requires:
+		return fmt.Sprintf("flag -%s requires flag: %s", e.Flags[0], e.names(e.Offending))
+	}
+	return fmt.Sprintf("flag constraint violated: %s", e.Constraint)
+}
+
+func (f *FlagSet) constrain(k ConstraintKind, names ...string) {
+	var msg string
+	switch {
+	case len(names) == 0, (k == Requires || k == Exclusive) && len(names) < 2:
+		msg = fmt.Sprintf("%s flag constraint %q has too few flags: %v", f.name, k, names)
+	default:
+		for _, n := range names {
+			if _, ok := f.formal[n]; !ok {
+				msg = fmt.Sprintf("%s flag not defined for constraint %q: %s", f.name, k, n)
+				break
+			}
+		}
+	}
+	if msg != "" {
+		fmt.Fprintln(f.Out(), msg)
+		panic(msg)
+	}
+	f.constraints = append(f.constraints, &Constraint{k, names})
+}
+
+// Marks the previously defined flags of the provided names as required. This
+// will panic for any undefined flag.
+func (f *FlagSet) SetRequired(names ...string) {
+	f.constrain(Required, names...)
+}
+
+// Marks the previously defined flags of the provided names as mutually
+// exclusive. This will panic for any undefined flag, or fewer than two flags.
+func (f *FlagSet) SetExclusive(names ...string) {
+	f.constrain(Exclusive, names...)
+}
+
+// Requires at least one of the previously defined flags of the provided names.
+// This will panic for any undefined flag.
+func (f *FlagSet) SetOneRequired(names ...string) {
+	f.constrain(OneRequired, names...)
+}
+
+// Requires every flag of the provided names be set when the named flag is set,
+// e.g. SetRequires("tls-key", "tls-cert"). This will panic for any undefined
+// flag.
+func (f *FlagSet) SetRequires(name string, requires ...string) {
+	f.constrain(Requires, append([]string{name}, requires...)...)
+}
+
+// Returns the Constraints of the *FlagSet in order of declaration.
+func (f *FlagSet) Constraints() []*Constraint {
+	return f.constraints
+}
+
+// Returns a boolean indicating if the flag of the provided name is required.
+func (f *FlagSet) IsRequired(name string) bool {
+	for _, c := range f.constraints {
+		if c.Kind == Required {
+			for _, n := range c.Flags {
+				if n == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (f *FlagSet) checkConstraints() error {
+	set := func(n string) bool {
+		_, ok := f.current[n]
+		return ok
+	}
+	provided := func(n string) bool {
+		_, ok := f.defaulted[n]
+		return ok || set(n)
+	}
+	for _, c := range f.constraints {
+		if err := c.check(set, provided); err != nil {
+			return failErr(f, err)
+		}
+	}
+	return nil
+}
+
+func (f *FlagSet) constraintUsage(o io.Writer) {
+	for _, c := range f.constraints {
+		if c.Kind != Required {
+			white(o, fmt.Sprintf("\t%s\n", c))
+		}
+	}
+}
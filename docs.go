@@ -0,0 +1,275 @@
+package flip
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// visits every visible group by priority, and every command of the group,
+// parents before children, by priority
+func visitCommands(cm Commander, fn func(*Group, Command)) {
+	gs := cm.Groups()
+	gs.SortGroupsBy("")
+	var walk func(*Group, []Command)
+	walk = func(g *Group, cs []Command) {
+		sortCommands(cs, "default")
+		for _, cmd := range cs {
+			fn(g, cmd)
+			walk(g, cmd.Children())
+		}
+	}
+	for _, g := range gs.Has {
+		if !g.Hidden {
+			walk(g, g.Commands)
+		}
+	}
+}
+
+func docFileName(name string, cmd Command, sep, ext string) string {
+	n := filepath.Base(name)
+	if cmd != nil {
+		n = n + sep + strings.Replace(cmd.Path(), " ", sep, -1)
+	}
+	return n + ext
+}
+
+var roffReplacer = strings.NewReplacer(`\`, `\e`, "-", `\-`)
+
+func roff(s string) string {
+	s = roffReplacer.Replace(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+func manFlags(o io.Writer, fs Flagger) {
+	fds := flagHelp(fs)
+	if len(fds) == 0 {
+		return
+	}
+	fmt.Fprint(o, ".SH OPTIONS\n")
+	for _, d := range fds {
+		fmt.Fprintf(o, ".TP\n\\fB%s\\fR", roff(d.Names))
+		if d.Type != "" {
+			fmt.Fprintf(o, " \\fI%s\\fR", roff(d.Type))
+		}
+		fmt.Fprintf(o, "\n%s\n", roff(d.Usage))
+		if d.Default != "" {
+			fmt.Fprintf(o, ".br\nDefault: %s\n", roff(d.Default))
+		}
+		if len(d.Env) > 0 {
+			fmt.Fprintf(o, ".br\nEnvironment: %s\n", roff(strings.Join(d.Env, ", ")))
+		}
+		if d.Required {
+			fmt.Fprint(o, ".br\nRequired.\n")
+		}
+		if d.Repeatable {
+			fmt.Fprint(o, ".br\nMay be repeated.\n")
+		}
+	}
+}
+
+// Writes a roff man page overview of the program of the provided name, listing
+// every command by group, to the provided io.Writer.
+func ManOverview(o io.Writer, name string, cm Commander) {
+	n := filepath.Base(name)
+	fmt.Fprintf(o, ".TH \"%s\" \"1\" \"\" \"%s\" \"%s Manual\"\n", strings.ToUpper(roff(n)), roff(n), roff(n))
+	fmt.Fprintf(o, ".SH NAME\n%s\n", roff(n))
+	fmt.Fprintf(o, ".SH SYNOPSIS\n\\fB%s\\fR [OPTIONS...] {COMMAND} ...\n", roff(n))
+	fmt.Fprint(o, ".SH COMMANDS\n")
+	var last *Group
+	var see []string
+	visitCommands(cm, func(g *Group, cmd Command) {
+		if g != last {
+			fmt.Fprintf(o, ".SS %s (group priority %d)\n", roff(groupTitle(g)), g.Priority)
+			last = g
+		}
+		fmt.Fprintf(o, ".TP\n\\fB%s\\fR\n%s\n", roff(cmd.Path()), roff(cmd.UseString()))
+		see = append(see, fmt.Sprintf("\\fB%s\\fR(1)", roff(docFileName(n, cmd, "-", ""))))
+	})
+	if len(see) > 0 {
+		fmt.Fprintf(o, ".SH SEE ALSO\n%s\n", strings.Join(see, ", "))
+	}
+}
+
+// Writes a roff man page of the provided Command of the program of the
+// provided name to the provided io.Writer.
+func ManPage(o io.Writer, name string, g *Group, cmd Command) {
+	n := filepath.Base(name)
+	page := docFileName(n, cmd, "-", "")
+	fmt.Fprintf(o, ".TH \"%s\" \"1\" \"\" \"%s\" \"%s Manual\"\n", strings.ToUpper(roff(page)), roff(n), roff(n))
+	fmt.Fprintf(o, ".SH NAME\n%s \\- %s\n", roff(page), roff(cmd.UseString()))
+	fmt.Fprintf(o, ".SH SYNOPSIS\n\\fB%s %s\\fR [<flags>]\n", roff(n), roff(cmd.Path()))
+	fmt.Fprintf(o, ".SH DESCRIPTION\n%s\n", roff(cmd.UseString()))
+	fmt.Fprintf(o, ".PP\nGroup %s, group priority %d, command priority %d.", roff(groupTitle(g)), g.Priority, cmd.Priority())
+	if cmd.Escapes() {
+		fmt.Fprint(o, " Arguments following this command are not processed as further commands.")
+	}
+	fmt.Fprint(o, "\n")
+	manFlags(o, cmd)
+	see := []string{fmt.Sprintf("\\fB%s\\fR(1)", roff(n))}
+	if p := cmd.Parent(); p != nil {
+		see = append(see, fmt.Sprintf("\\fB%s\\fR(1)", roff(docFileName(n, p, "-", ""))))
+	}
+	for _, ch := range cmd.Children() {
+		see = append(see, fmt.Sprintf("\\fB%s\\fR(1)", roff(docFileName(n, ch, "-", ""))))
+	}
+	fmt.Fprintf(o, ".SH SEE ALSO\n%s\n", strings.Join(see, ", "))
+}
+
+func groupTitle(g *Group) string {
+	if g.Name == "" {
+		return "default"
+	}
+	return g.Name
+}
+
+func mdEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+// Writes a Markdown overview of the program of the provided name, listing and
+// linking every command by group, to the provided io.Writer.
+func MarkdownOverview(o io.Writer, name string, cm Commander) {
+	n := filepath.Base(name)
+	fmt.Fprintf(o, "# %s\n\n```\n%s [OPTIONS...] {COMMAND} ...\n```\n", n, n)
+	var last *Group
+	visitCommands(cm, func(g *Group, cmd Command) {
+		if g != last {
+			fmt.Fprintf(o, "\n## %s\n\nGroup priority %d.\n\n", groupTitle(g), g.Priority)
+			last = g
+		}
+		fmt.Fprintf(o, "- [%s](%s): %s\n", cmd.Path(), docFileName(n, cmd, "_", ".md"), cmd.UseString())
+	})
+}
+
+// Writes a Markdown page of the provided Command of the program of the
+// provided name to the provided io.Writer.
+func MarkdownPage(o io.Writer, name string, g *Group, cmd Command) {
+	n := filepath.Base(name)
+	fmt.Fprintf(o, "# %s %s\n\n%s\n\n", n, cmd.Path(), cmd.UseString())
+	fmt.Fprintf(o, "```\n%s %s [<flags>]\n```\n\n", n, cmd.Path())
+	fmt.Fprintf(o, "Group %s, group priority %d, command priority %d.", groupTitle(g), g.Priority, cmd.Priority())
+	if cmd.Escapes() {
+		fmt.Fprint(o, " Arguments following this command are not processed as further commands.")
+	}
+	fmt.Fprint(o, "\n")
+	if fds := flagHelp(cmd); len(fds) > 0 {
+		fmt.Fprint(o, "\n## Flags\n\n| Flag | Type | Default | Description |\n| --- | --- | --- | --- |\n")
+		for _, d := range fds {
+			desc := d.Usage
+			if len(d.Env) > 0 {
+				desc += fmt.Sprintf(" (env `$%s`)", strings.Join(d.Env, "`, `$"))
+			}
+			if d.Required {
+				desc += " (required)"
+			}
+			if d.Repeatable {
+				desc += " (repeatable)"
+			}
+			def := ""
+			if d.Default != "" {
+				def = "`" + d.Default + "`"
+			}
+			fmt.Fprintf(o, "| `%s` | %s | %s | %s |\n", d.Names, d.Type, mdEscape(def), mdEscape(desc))
+		}
+	}
+	if cs := cmd.Children(); len(cs) > 0 {
+		fmt.Fprint(o, "\n## Commands\n\n")
+		for _, ch := range cs {
+			fmt.Fprintf(o, "- [%s](%s): %s\n", ch.Path(), docFileName(n, ch, "_", ".md"), ch.UseString())
+		}
+	}
+	fmt.Fprintf(o, "\nSee [%s](%s).\n", n, docFileName(n, nil, "_", ".md"))
+}
+
+func writeDoc(path string, fn func(io.Writer)) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	fn(f)
+	return f.Close()
+}
+
+// Writes man pages of the program of the provided name, an overview (e.g.
+// name.1) and one per command (e.g. name-remote-add.1), to the provided
+// directory, creating it if necessary.
+func WriteManPages(dir, name string, cm Commander) error {
+	return writeDocs(dir, name, cm, "-", ".1", ManOverview, ManPage)
+}
+
+// Writes Markdown files of the program of the provided name, an overview (e.g.
+// name.md) and one per command (e.g. name_remote_add.md), to the provided
+// directory, creating it if necessary.
+func WriteMarkdown(dir, name string, cm Commander) error {
+	return writeDocs(dir, name, cm, "_", ".md", MarkdownOverview, MarkdownPage)
+}
+
+func writeDocs(dir, name string, cm Commander, sep, ext string,
+	ofn func(io.Writer, string, Commander),
+	pfn func(io.Writer, string, *Group, Command)) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	err := writeDoc(filepath.Join(dir, docFileName(name, nil, sep, ext)), func(o io.Writer) {
+		ofn(o, name, cm)
+	})
+	visitCommands(cm, func(g *Group, cmd Command) {
+		if err != nil {
+			return
+		}
+		err = writeDoc(filepath.Join(dir, docFileName(name, cmd, sep, ext)), func(o io.Writer) {
+			pfn(o, name, g, cmd)
+		})
+	})
+	return err
+}
+
+type docs struct {
+	f           *flipper
+	format, dir string
+}
+
+func docsFlag(d *docs) *FlagSet {
+	fs := NewFlagSet("docs", ContinueOnError)
+	fs.EnumVar(&d.format, "format", "man", []string{"man", "markdown"}, "The documentation format to write.")
+	fs.StringVar(&d.dir, "dir", "docs", "The directory to write documentation to.")
+	fs.SetCompleter("dir", CompleteDirs())
+	return fs
+}
+
+func (d *docs) command() Command {
+	return NewCommand(
+		"",
+		"docs",
+		`Writes man page or Markdown documentation of every command.`,
+		1,
+		true,
+		func(c context.Context, a []string) (context.Context, ExitStatus) {
+			wfn := WriteManPages
+			if d.format == "markdown" {
+				wfn = WriteMarkdown
+			}
+			err := wfn(d.dir, d.f.name, d.f.Commander)
+			d.format, d.dir = "man", "docs"
+			if err != nil {
+				fmt.Fprintln(d.f.Out(), err)
+				return c, ExitFailure
+			}
+			return c, ExitSuccess
+		},
+		docsFlag(d),
+	)
+}
+
+func (f *flipper) addDocs() *flipper {
+	d := &docs{f, "man", "docs"}
+	f.SetGroup("docs", -1000, d.command())
+	return f
+}
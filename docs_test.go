@@ -0,0 +1,102 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func docsFlipper(to *bytes.Buffer) *flipper {
+	fs := NewFlagSet("push", ContinueOnError)
+	fs.String("name", "origin", "the remote name")
+	fs.SetShort("name", "n")
+	fs.SetEnv("name", "PUSH_NAME")
+	fs.StringSlice("tag", nil, "tags to push")
+	fs.Bool("force", false, "force the push")
+	fs.SetRequired("name")
+	var ran []string
+	remote := subCmdSet(&ran, to)
+	remote.SetChild(NewCommand("", "push", "push to a remote", 2, false, nil, fs))
+	f := New("test")
+	f.SetOut(to)
+	f.AddBuiltIn("completion").
+		AddBuiltIn("docs").
+		SetGroup("remote", 1, remote)
+	return f
+}
+
+func TestDocs(t *testing.T) {
+	b := new(bytes.Buffer)
+	f := docsFlipper(b)
+	push := f.GetCommand("remote push")[0]
+	g := f.GetGroup("remote")
+
+	b.Reset()
+	ManPage(b, "test", g, push)
+	man := b.String()
+	for _, expect := range []string{
+		`.TH "TEST\-REMOTE\-PUSH" "1"`,
+		".SH NAME\ntest\\-remote\\-push \\- push to a remote",
+		`\fBtest remote push\fR [<flags>]`,
+		"group priority 1, command priority 2",
+		`\fB\-n, \-\-name\fR \fIstring\fR`,
+		"Default: origin",
+		"Environment: PUSH_NAME",
+		"Required.",
+		"May be repeated.",
+		`\fBtest\-remote\fR(1)`,
+	} {
+		if !strings.Contains(man, expect) {
+			t.Errorf("expected man page to contain %q:\n%s", expect, man)
+		}
+	}
+
+	b.Reset()
+	ManOverview(b, "test", f.Commander)
+	man = b.String()
+	if !strings.Contains(man, `\fBremote push\fR`) || strings.Contains(man, "__complete") {
+		t.Errorf("unexpected man overview:\n%s", man)
+	}
+
+	b.Reset()
+	MarkdownPage(b, "test", g, push)
+	md := b.String()
+	for _, expect := range []string{
+		"# test remote push",
+		"| `-n, --name` | string | `origin` | the remote name (env `$PUSH_NAME`) (required) |",
+		"| `-force` |  |  | force the push |",
+		"See [test](test.md).",
+	} {
+		if !strings.Contains(md, expect) {
+			t.Errorf("expected markdown page to contain %q:\n%s", expect, md)
+		}
+	}
+
+	b.Reset()
+	MarkdownOverview(b, "test", f.Commander)
+	if md = b.String(); !strings.Contains(md, "- [remote push](test_remote_push.md): push to a remote") {
+		t.Errorf("unexpected markdown overview:\n%s", md)
+	}
+
+	dir := t.TempDir()
+	for format, files := range map[string][]string{
+		"man":      {"test.1", "test-remote.1", "test-remote-show-detail.1", "test-docs.1"},
+		"markdown": {"test.md", "test_remote.md", "test_remote_show_detail.md", "test_completion.md"},
+	} {
+		out := filepath.Join(dir, format)
+		if res := f.Execute(context.Background(), []string{"test", "docs", "-format", format, "-dir", out}); res != 0 {
+			t.Fatalf("docs %s failed: %s", format, b.String())
+		}
+		for _, n := range files {
+			if _, err := os.Stat(filepath.Join(out, n)); err != nil {
+				t.Errorf("expected %s documentation file: %v", format, err)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(out, "test-__complete.1")); err == nil {
+			t.Error("hidden command documented")
+		}
+	}
+}
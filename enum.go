@@ -0,0 +1,115 @@
+package flip
+
+import (
+	"fmt"
+	"strings"
+)
+
+// An interface for flag Values restricted to a set of choices, listed in
+// usage and completion.
+type Chooser interface {
+	Value
+	Choices() []string
+}
+
+type enumValue struct {
+	choices []string
+	fold    bool
+	sfn     setFn
+	gfn     getFn
+}
+
+func newEnumValue(val string, p *string, choices []string) *enumValue {
+	*p = val
+	return &enumValue{
+		choices,
+		false,
+		func(n string) error {
+			*p = n
+			return nil
+		},
+		func() interface{} { return *p },
+	}
+}
+
+func enumContainValue(key, value string, v StringContain, choices []string) *enumValue {
+	v.SetString(key, value)
+	return &enumValue{
+		choices,
+		false,
+		func(n string) error {
+			v.SetString(key, n)
+			return nil
+		},
+		func() interface{} {
+			return v.ToString(key)
+		},
+	}
+}
+
+// Value interface Set function for internal type enumValue, returning an error
+// for any string not among the choices.
+func (e *enumValue) Set(s string) error {
+	for _, c := range e.choices {
+		if s == c || (e.fold && strings.EqualFold(s, c)) {
+			return e.sfn(c)
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(e.choices, ", "))
+}
+
+// Value interface Get function for internal type enumValue
+func (e *enumValue) Get() interface{} {
+	return e.gfn()
+}
+
+// Value interface String function for internal type enumValue
+func (e *enumValue) String() string {
+	return fmt.Sprintf("%v", e.Get())
+}
+
+// Chooser interface Choices function for internal type enumValue
+func (e *enumValue) Choices() []string {
+	return e.choices
+}
+
+// Sets the previously defined enum flag of the provided name to match its
+// choices regardless of case, setting the matched choice as the value. This
+// will panic for an undefined or non enum flag.
+func (f *FlagSet) SetCaseInsensitive(name string) {
+	if flag, ok := f.formal[name]; ok {
+		if ev, ok := flag.Value.(*enumValue); ok {
+			ev.fold = true
+			return
+		}
+	}
+	msg := fmt.Sprintf("%s enum flag not defined for case insensitivity: %s", f.name, name)
+	fmt.Fprintln(f.Out(), msg)
+	panic(msg)
+}
+
+// Defines a string flag restricted to the provided choices (e.g. -format
+// json|yaml|text), setting the provided string pointer.
+func (f *FlagSet) EnumVar(p *string, name, value string, choices []string, usage string) {
+	f.Var(newEnumValue(value, p, choices), name, usage)
+}
+
+// Defines a string flag restricted to the provided choices, returning a string
+// pointer.
+func (f *FlagSet) Enum(name, value string, choices []string, usage string) *string {
+	p := new(string)
+	f.EnumVar(p, name, value, choices, usage)
+	return p
+}
+
+// Defines a contain backed string flag restricted to the provided choices.
+func (f *FlagSet) EnumContainVar(d StringContain, name, key, value string, choices []string, usage string) {
+	f.Var(enumContainValue(key, value, d, choices), name, usage)
+}
+
+// Defines a contain backed string flag restricted to the provided choices,
+// returning the container.
+func (f *FlagSet) EnumContain(d StringContain, name, key string, choices []string, usage string) StringContain {
+	f.EnumContainVar(d, name, key, "", choices, usage)
+	return d
+}
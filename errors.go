@@ -0,0 +1,116 @@
+package flip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// The error returned from ExecuteE when no command is found in the
+// arguments, or every command found returns ExitNo.
+var ErrNoCommand = errors.New("no command completed")
+
+// The error returned from setting a template when the Instructer is not a
+// Templater.
+var ErrNoTemplates = errors.New("instructer does not support templates")
+
+// An error type returned from parsing a flag not defined in the FlagSet.
+type UnknownFlagError struct {
+	Flag string
+}
+
+// Returns the UnknownFlagError as a string.
+func (e *UnknownFlagError) Error() string {
+	return fmt.Sprintf("flag provided but not defined: -%s", e.Flag)
+}
+
+// An error type returned from parsing a non boolean flag without a value.
+type MissingValueError struct {
+	Flag string
+}
+
+// Returns the MissingValueError as a string.
+func (e *MissingValueError) Error() string {
+	return fmt.Sprintf("flag needs an argument: -%s", e.Flag)
+}
+
+// An error type returned from parsing a flag value the flag Value cannot
+// set, wrapping the error of the flag Value. Env is the environment variable
+// the value is from, if any.
+type InvalidValueError struct {
+	Flag  string
+	Value string
+	Env   string
+	Err   error
+}
+
+// Returns the InvalidValueError as a string.
+func (e *InvalidValueError) Error() string {
+	if e.Env != "" {
+		return fmt.Sprintf("invalid value %q for flag -%s from environment variable %s: %v", e.Value, e.Flag, e.Env, e.Err)
+	}
+	return fmt.Sprintf("invalid value %q for flag -%s: %v", e.Value, e.Flag, e.Err)
+}
+
+// Returns the error of the flag Value.
+func (e *InvalidValueError) Unwrap() error {
+	return e.Err
+}
+
+// An error type returned from ExecuteE when a Command fails, i.e. returns an
+// ExitStatus other than ExitSuccess or ExitNo, wrapping any error the Command
+// provided by WithError.
+type CommandError struct {
+	Command Command
+	Status  ExitStatus
+	Err     error
+}
+
+// Returns the CommandError as a string.
+func (e *CommandError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("command %s failed: %v", e.Command.Path(), e.Err)
+	}
+	return fmt.Sprintf("command %s failed with status %d", e.Command.Path(), e.Status)
+}
+
+// Returns the error provided by the Command, if any.
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+type errorKey struct{}
+
+// Returns a context.Context carrying the provided error, for a CommandFunc to
+// return with a failing ExitStatus as the cause of the CommandError.
+func WithError(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, errorKey{}, err)
+}
+
+// Returns the error carried by the provided context.Context by WithError, or
+// nil.
+func ErrorFrom(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	err, _ := ctx.Value(errorKey{}).(error)
+	return err
+}
+
+// An error type returned from ExecuteScript for a line failing to execute,
+// wrapping the error of the line. Line is the number of the first line of the
+// command line in the script.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+// Returns the LineError as a string.
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Returns the error of the line.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,149 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var errBroken = errors.New("broken")
+
+func errorFlipper(b *bytes.Buffer) *flipper {
+	fs := NewFlagSet("run", ContinueOnError)
+	fs.Int("count", 1, "a count")
+	fs.Bool("json", false, "json output")
+	fs.Bool("yaml", false, "yaml output")
+	fs.SetExclusive("json", "yaml")
+	fs.SetOut(b)
+	f := New("test")
+	f.SetOut(b)
+	f.SetGroup("run", 1,
+		NewCommand("", "prep", "prep command", 1, false,
+			func(c context.Context, a []string) (context.Context, ExitStatus) {
+				return c, ExitNo
+			},
+			NewFlagSet("prep", ContinueOnError),
+		),
+		NewCommand("", "run", "run command", 2, false,
+			func(c context.Context, a []string) (context.Context, ExitStatus) {
+				return c, ExitSuccess
+			},
+			fs,
+		),
+		NewCommand("", "fail", "fail command", 3, false,
+			func(c context.Context, a []string) (context.Context, ExitStatus) {
+				return WithError(c, errBroken), ExitFailure
+			},
+			NewFlagSet("fail", ContinueOnError),
+		),
+	)
+	return f
+}
+
+func TestExecuteE(t *testing.T) {
+	b := new(bytes.Buffer)
+	f := errorFlipper(b)
+
+	r, err := f.ExecuteE(context.Background(), []string{"test", "prep", "run", "-count", "2"})
+	if err != nil || r.Status != ExitSuccess || len(r.Ran) != 2 || r.Ran[1].Tag() != "run" || r.Context == nil {
+		t.Errorf("unexpected result %+v, %v", r, err)
+	}
+
+	var unknown *UnknownFlagError
+	r, err = f.ExecuteE(context.Background(), []string{"test", "run", "-nope"})
+	if !errors.As(err, &unknown) || unknown.Flag != "nope" || r.Status != ExitUsageError || len(r.Ran) != 0 {
+		t.Errorf("expected unknown flag error, got %+v, %v", r, err)
+	}
+
+	var missing *MissingValueError
+	_, err = f.ExecuteE(context.Background(), []string{"test", "run", "-count"})
+	if !errors.As(err, &missing) || missing.Flag != "count" {
+		t.Errorf("expected missing value error, got %v", err)
+	}
+
+	var invalid *InvalidValueError
+	_, err = f.ExecuteE(context.Background(), []string{"test", "run", "-count", "x"})
+	if !errors.As(err, &invalid) || invalid.Flag != "count" || invalid.Value != "x" || errors.Unwrap(err) == nil {
+		t.Errorf("expected invalid value error, got %v", err)
+	}
+
+	var constraint *ConstraintError
+	_, err = f.ExecuteE(context.Background(), []string{"test", "run", "-json", "-yaml"})
+	if !errors.As(err, &constraint) || constraint.Kind != Exclusive {
+		t.Errorf("expected constraint error, got %v", err)
+	}
+
+	var failed *CommandError
+	r, err = f.ExecuteE(context.Background(), []string{"test", "fail"})
+	if !errors.As(err, &failed) || failed.Command.Tag() != "fail" || failed.Status != ExitFailure ||
+		!errors.Is(err, errBroken) || r.Status != ExitFailure {
+		t.Errorf("expected command error, got %+v, %v", r, err)
+	}
+
+	for _, args := range [][]string{{"test"}, {"test", "nothing"}, {"test", "prep"}} {
+		r, err = f.ExecuteE(context.Background(), args)
+		if err != ErrNoCommand || r.Status != ExitUsageError {
+			t.Errorf("%v: expected no command error, got %+v, %v", args, r, err)
+		}
+	}
+
+	if res := f.Execute(context.Background(), []string{"test", "fail"}); res != int(ExitFailure) {
+		t.Errorf("expected Execute %d, got %d", ExitFailure, res)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	b := new(bytes.Buffer)
+	f := errorFlipper(b)
+	const ExitConflict ExitStatus = -409
+	f.SetExitCode(ExitConflict, 9)
+	var cleaned []string
+	f.SetCleanup(ExitConflict, func(context.Context) { cleaned = append(cleaned, "conflict") })
+	f.SetCleanup(ExitFailure, func(context.Context) { cleaned = append(cleaned, "failure") })
+	f.SetGroup("conflict", -1,
+		NewCommand("", "conflict", "conflict command", 1, false,
+			func(c context.Context, a []string) (context.Context, ExitStatus) {
+				return c, ExitConflict
+			},
+			NewFlagSet("conflict", ContinueOnError),
+		),
+	)
+
+	for _, e := range []struct {
+		args    []string
+		status  ExitStatus
+		code    int
+		cleaned string
+	}{
+		{[]string{"test", "run"}, ExitSuccess, 0, ""},
+		{[]string{"test", "fail"}, ExitFailure, 1, "failure"},
+		{[]string{"test", "run", "-nope"}, ExitUsageError, 2, ""},
+		{[]string{"test", "conflict", "run"}, ExitConflict, 9, "conflict"},
+	} {
+		cleaned = nil
+		r, _ := f.ExecuteE(context.Background(), e.args)
+		if r.Status != e.status || r.Code != e.code || strings.Join(cleaned, ",") != e.cleaned {
+			t.Errorf("%v: expected %d (code %d, cleaned %q), got %d (code %d, cleaned %v)",
+				e.args, e.status, e.code, e.cleaned, r.Status, r.Code, cleaned)
+		}
+	}
+
+	for s, code := range map[ExitStatus]int{ExitConfigError: 78, ExitNoPerm: 77, 3: 3, -3: 1, 300: 1} {
+		if c := f.ExitCode(s); c != code {
+			t.Errorf("expected exit code %d for %d, got %d", code, s, c)
+		}
+	}
+	f.SetExitCode(ExitFailure, 3)
+	if c := f.ExitCode(ExitFailure); c != 3 {
+		t.Errorf("expected remapped exit code 3, got %d", c)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for invalid exit code")
+		}
+	}()
+	f.SetExitCode(ExitConflict, 256)
+}
@@ -0,0 +1,61 @@
+package flip
+
+import (
+	"fmt"
+	"os"
+)
+
+// An interface for mapping an ExitStatus to a valid process exit code, in the
+// range 0 to 255.
+type ExitCoder interface {
+	SetExitCode(ExitStatus, int)
+	ExitCode(ExitStatus) int
+}
+
+type exitCoder struct {
+	codes map[ExitStatus]int
+}
+
+func newExitCoder() *exitCoder {
+	return &exitCoder{map[ExitStatus]int{
+		ExitSuccess:     0,
+		ExitFailure:     1,
+		ExitUsageError:  2,
+		ExitDataError:   65,
+		ExitNoInput:     66,
+		ExitUnavailable: 69,
+		ExitSoftware:    70,
+		ExitIOError:     74,
+		ExitTempFail:    75,
+		ExitNoPerm:      77,
+		ExitConfigError: 78,
+		ExitInterrupted: 130,
+		ExitPanic:       70,
+	}}
+}
+
+// Sets the process exit code of the provided ExitStatus, either remapping a
+// package ExitStatus or registering a custom ExitStatus a Command may return.
+// A Command returning any ExitStatus other than ExitSuccess or ExitNo stops
+// execution, running the Cleanup functions set for that ExitStatus. This will
+// panic for ExitNo, ExitAny, or a code outside 0 to 255.
+func (e *exitCoder) SetExitCode(s ExitStatus, code int) {
+	if s == ExitNo || s == ExitAny || code < 0 || code > 255 {
+		msg := fmt.Sprintf("invalid exit code %d for exit status %d", code, s)
+		fmt.Fprintln(os.Stderr, msg)
+		panic(msg)
+	}
+	e.codes[s] = code
+}
+
+// Returns the process exit code of the provided ExitStatus: the code set, the
+// ExitStatus itself for an unset ExitStatus from 0 to 255, or 1.
+func (e *exitCoder) ExitCode(s ExitStatus) int {
+	if code, ok := e.codes[s]; ok {
+		return code
+	}
+	if s >= 0 && s <= 255 {
+		return int(s)
+	}
+	return 1
+}
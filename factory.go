@@ -0,0 +1,58 @@
+package flip
+
+import (
+	"context"
+	"fmt"
+)
+
+// A type executing every invocation with a new Flipper from a function, so
+// that no flag values, FlagSet, builtin, or Cleaner state is shared between
+// invocations and concurrent calls to Execute are safe, e.g. in a server
+// executing commands per request. The function must define new flag
+// variables, Commands, and FlagSets on every call.
+type Factory struct {
+	fn         func() Flipper
+	middleware []Middleware
+}
+
+// Returns a new *Factory of Flippers from the provided function.
+func NewFactory(fn func() Flipper) *Factory {
+	return &Factory{fn: fn}
+}
+
+// Returns a new Flipper from the Factory function, with any Factory
+// Middleware set if the Flipper is a Middlewarer, e.g. to set its output
+// before executing.
+func (f *Factory) New() Flipper {
+	fl := f.fn()
+	if m, ok := fl.(Middlewarer); ok && len(f.middleware) > 0 {
+		m.SetMiddleware(f.middleware...)
+	}
+	return fl
+}
+
+// Set the provided Middleware to wrap execution of every Command of every
+// new Flipper. This is not safe to call concurrently with Execute.
+func (f *Factory) SetMiddleware(mws ...Middleware) {
+	f.middleware = append(f.middleware, mws...)
+}
+
+// Executes the provided arguments with a new Flipper as Flipper Execute.
+func (f *Factory) Execute(ctx context.Context, arguments []string) int {
+	return f.New().Execute(ctx, arguments)
+}
+
+// Executes the provided arguments with a new Flipper as Flipper ExecuteE.
+func (f *Factory) ExecuteE(ctx context.Context, arguments []string) (*Result, error) {
+	return f.New().ExecuteE(ctx, arguments)
+}
+
+// Executes the provided command line with a new Flipper as LineExecuter
+// ExecuteLine, returning an error for a Flipper not a LineExecuter.
+func (f *Factory) ExecuteLine(ctx context.Context, line string) (*Result, error) {
+	fl := f.New()
+	if le, ok := fl.(LineExecuter); ok {
+		return le.ExecuteLine(ctx, line)
+	}
+	return &Result{Status: ExitUsageError, Context: ctx}, fmt.Errorf("%T does not execute lines", fl)
+}
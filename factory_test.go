@@ -0,0 +1,76 @@
+package flip
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type greetKey struct{}
+
+func greetFlipper() Flipper {
+	var name string
+	var tags []string
+	fs := NewFlagSet("greet", ContinueOnError)
+	fs.StringVar(&name, "name", "nobody", "a name")
+	fs.StringSliceVar(&tags, "tag", nil, "tags")
+	fs.SetOut(io.Discard)
+	f := New("test")
+	f.SetOut(io.Discard)
+	f.AddBuiltIn("help").
+		AddBuiltIn("version", "pkg", "tag").
+		SetGroup("greet", 1,
+			NewCommand("", "greet", "greets", 1, false,
+				func(c context.Context, a []string) (context.Context, ExitStatus) {
+					return context.WithValue(c, greetKey{}, fmt.Sprintf("%s %s", name, strings.Join(tags, ","))), ExitSuccess
+				},
+				fs,
+			),
+		)
+	return f
+}
+
+func TestFactory(t *testing.T) {
+	fa := NewFactory(greetFlipper)
+	var mws int64
+	fa.SetMiddleware(func(next CommandFunc) CommandFunc {
+		atomic.AddInt64(&mws, 1)
+		return next
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("n%d", i)
+			args := []string{"test", "greet", "-name", name}
+			expect := name + " "
+			if i%2 == 0 {
+				args = append(args, "-tag", "a", "-tag", "b")
+				expect = name + " a,b"
+			}
+			for j := 0; j < 3; j++ {
+				r, err := fa.ExecuteE(context.Background(), args)
+				if err != nil || r.Context.Value(greetKey{}) != expect {
+					t.Errorf("%v: expected %q, got %v, %v", args, expect, r.Context.Value(greetKey{}), err)
+				}
+			}
+			r, _ := fa.ExecuteE(context.Background(), []string{"test", "greet"})
+			if r.Context.Value(greetKey{}) != "nobody " {
+				t.Errorf("expected defaults on a new Flipper, got %v", r.Context.Value(greetKey{}))
+			}
+			if res := fa.Execute(context.Background(), []string{"test", "help", "-commands", "greet"}); res != 0 {
+				t.Errorf("unexpected help result %d", res)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if mws != 32*5 {
+		t.Errorf("expected factory middleware on every execution, got %d", mws)
+	}
+}
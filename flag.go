@@ -13,10 +13,23 @@ import (
 
 // A type representing one command line flag
 type Flag struct {
-	Name     string // name as it appears on command line
-	Message  string // help message
-	Value    Value  // value as set
-	DefValue string // default value (as text); for usage message
+	Name     string   // name as it appears on command line
+	Message  string   // help message
+	Value    Value    // value as set
+	DefValue string   // default value (as text); for usage message
+	Short    string   // optional one letter alias, e.g. "v" for -v
+	EnvVars  []string // optional environment variables consulted when not on command line
+
+	defs []string // default elements of a repeatable flag, restored by Reset
+}
+
+// Returns the command line form of the Flag names, e.g. "-v, --verbose", or
+// "-verbose" for a Flag without a short name.
+func (f *Flag) Names() string {
+	if f.Short != "" {
+		return fmt.Sprintf("-%s, --%s", f.Short, f.Name)
+	}
+	return fmt.Sprintf("-%s", f.Name)
 }
 
 // A package level interface for abstracting flag values
@@ -430,27 +443,54 @@ func (r *regexValue) Get() interface{} {
 //
 func (r *regexValue) String() string { return r.raw }
 
+// the value of a regex flag is held by its bridge function, which Reset does
+// not call with the raw expressions
+func (r *regexValue) record() {}
+
+func (r *regexValue) restore() error { return nil }
+
 //
 type RgxContainBridgeFunc func(string, StringContain, ...*regexp.Regexp) error
 
-func regexContainValue(key string, xfn RgxContainBridgeFunc, v StringContain, raw ...string) *containValue {
+type regexContainValue struct {
+	*containValue
+	v   StringContain
+	def string
+}
+
+func newRegexContainValue(key string, xfn RgxContainBridgeFunc, v StringContain, raw ...string) *regexContainValue {
 	v.SetString(key, strings.Join(raw, ","))
 	var rgx []*regexp.Regexp
 	for _, r := range raw {
 		rgx = append(rgx, regexp.MustCompile(r))
 	}
-	return &containValue{
-		key,
-		func(n string) error {
-			return xfn(n, v, rgx...)
-		},
-		func() interface{} {
-			return v.ToString(key)
+	return &regexContainValue{
+		&containValue{
+			key,
+			func(n string) error {
+				return xfn(n, v, rgx...)
+			},
+			func() interface{} {
+				return v.ToString(key)
+			},
+			"regex",
 		},
-		"regex",
+		v,
+		"",
 	}
 }
 
+func (r *regexContainValue) record() {
+	r.def = r.v.ToString(r.to)
+}
+
+// restores the contained value directly, as the bridge function takes values
+// matching the expressions only
+func (r *regexContainValue) restore() error {
+	r.v.SetString(r.to, r.def)
+	return nil
+}
+
 // An integer type representing method for handling errors.
 type ErrorHandling int
 
@@ -475,7 +515,13 @@ type FlagSet struct {
 	name          string
 	parsed        bool
 	actual        map[string]*Flag
+	current       map[string]*Flag
+	defaulted     map[string]*Flag
 	formal        map[string]*Flag
+	shorts        map[string]*Flag
+	envPrefix     string
+	constraints   []*Constraint
+	completers    map[string]*Completer
 	args          []string
 	errorHandling ErrorHandling
 	output        io.Writer
@@ -496,18 +542,27 @@ type GetterSetter interface {
 	Var(Value, string, string)
 }
 
-// Return a *Flag by the provided name, or nil if nothing is found.
+// An optional interface of a GetterSetter for setting flag defaults, e.g. from
+// a Config, implemented by *FlagSet and Commands of NewCommand.
+type Defaulter interface {
+	SetDefault(string, ...string) error
+}
+
+// Return a *Flag by the provided name or short name, or nil if nothing is found.
 func (f *FlagSet) Lookup(name string) *Flag {
 	if fl, ok := f.formal[name]; ok {
 		return fl
 	}
+	if fl, ok := f.shorts[name]; ok {
+		return fl
+	}
 	return nil
 }
 
-// Sets a flag by string name and value, returning an error.
+// Sets a flag by string name or short name and value, returning an error.
 func (f *FlagSet) Set(name, value string) error {
-	flag, ok := f.formal[name]
-	if !ok {
+	flag := f.Lookup(name)
+	if flag == nil {
 		return fmt.Errorf("no such flag -%v", name)
 	}
 	err := flag.Value.Set(value)
@@ -517,10 +572,86 @@ func (f *FlagSet) Set(name, value string) error {
 	if f.actual == nil {
 		f.actual = make(map[string]*Flag)
 	}
-	f.actual[name] = flag
+	f.actual[flag.Name] = flag
+	return nil
+}
+
+// Sets the provided values, in order, to the flag of the provided name or short
+// name as its default, without marking the flag as set. Command line and
+// environment values take precedence over a default set this way, and it
+// satisfies Required, OneRequired, and Requires constraints (see Constraint).
+func (f *FlagSet) SetDefault(name string, values ...string) error {
+	flag := f.Lookup(name)
+	if flag == nil {
+		return fmt.Errorf("no such flag -%v", name)
+	}
+	rv, repeatable := flag.Value.(repeatableFlag)
+	if repeatable {
+		rv.reDefault()
+	}
+	for _, v := range values {
+		if err := flag.Value.Set(v); err != nil {
+			return err
+		}
+	}
+	if repeatable {
+		rv.reDefault()
+	}
+	flag.DefValue = flag.Value.String()
+	flag.defs = sliceElements(flag.Value)
+	if f.defaulted == nil {
+		f.defaulted = make(map[string]*Flag)
+	}
+	f.defaulted[flag.Name] = flag
+	if r, ok := flag.Value.(restorer); ok {
+		r.record()
+	}
 	return nil
 }
 
+// Restores every flag to its default value, and clears the flags set, the
+// arguments, and the parsed state, so that the FlagSet parses as new. Returns
+// an error for any flag not restored from its default. A flag of RegexVar is
+// the exception: its value is held by its bridge function, which is not
+// called, and keeps the value last set until set again.
+func (f *FlagSet) Reset() error {
+	var err error
+	for _, flag := range sortFlags(f.formal) {
+		if rerr := resetFlag(flag); rerr != nil && err == nil {
+			err = fmt.Errorf("cannot reset flag -%s: %v", flag.Name, rerr)
+		}
+	}
+	f.actual, f.current, f.args, f.parsed = nil, nil, nil, false
+	return err
+}
+
+// internal interface for flag Values whose default cannot be restored by
+// setting the default as text, recording their default when defined or by
+// SetDefault and restoring it by Reset
+type restorer interface {
+	record()
+	restore() error
+}
+
+func resetFlag(flag *Flag) error {
+	switch v := flag.Value.(type) {
+	case restorer:
+		return v.restore()
+	case *sliceValue:
+		v.clear()
+		for _, d := range flag.defs {
+			if err := v.add(d); err != nil {
+				return err
+			}
+		}
+		v.set = false
+		return nil
+	case *enumValue:
+		return v.sfn(flag.DefValue)
+	}
+	return flag.Value.Set(flag.DefValue)
+}
+
 // An interface handling flag parsing from a string slice, and returning details
 // of parsing status.
 type Parser interface {
@@ -532,13 +663,18 @@ type Parser interface {
 func (f *FlagSet) Parse(arguments []string) error {
 	f.parsed = true
 	f.args = arguments
+	f.current = nil
 	for {
 		seen, err := f.parseOne()
 		if seen {
 			continue
 		}
 		if err == nil {
-			break
+			if err = f.parseEnv(); err == nil {
+				if err = f.checkConstraints(); err == nil {
+					break
+				}
+			}
 		}
 		switch f.errorHandling {
 		case ContinueOnError:
@@ -575,13 +711,14 @@ func (f *FlagSet) parseOne() (bool, error) {
 
 	// it's a flag. does it have an argument?
 	f.args = f.args[1:]
+	long := name
 	hasValue := false
 	value := ""
-	for i := 1; i < len(name); i++ { // equals cannot be first
-		if name[i] == '=' {
-			value = name[i+1:]
+	for i := 1; i < len(long); i++ { // equals cannot be first
+		if long[i] == '=' {
+			value = long[i+1:]
 			hasValue = true
-			name = name[0:i]
+			long = long[0:i]
 			break
 		}
 	}
@@ -589,19 +726,55 @@ func (f *FlagSet) parseOne() (bool, error) {
 	m := f.formal
 	var flag *Flag
 	var exists bool
-	flag, exists = m[name]
+	flag, exists = m[long]
 	if !exists {
-		return false, failOnly(f, "flag provided but not defined: -%s\n", name)
+		// a single minus may lead one or more bundled short flags
+		if _, short := f.shorts[name[:1]]; short && numMinuses == 1 {
+			return f.parseShorts(name)
+		}
+		return false, failOnly(f, &UnknownFlagError{long})
 	}
 
+	return f.parseValue(flag, long, hasValue, value)
+}
+
+// parses a cluster of short flags, e.g. "abc" from -abc, where every short
+// flag is boolean except possibly the last, which takes the remainder of the
+// cluster (-ofile, -o=file) or the next argument as its value
+func (f *FlagSet) parseShorts(cluster string) (bool, error) {
+	for i := 0; i < len(cluster); i++ {
+		short := cluster[i : i+1]
+		flag, exists := f.shorts[short]
+		if !exists {
+			return false, failOnly(f, &UnknownFlagError{short})
+		}
+		rest := cluster[i+1:]
+		if fv, ok := flag.Value.(boolFlag); ok && fv.IsBoolFlag() {
+			if strings.HasPrefix(rest, "=") {
+				return f.parseValue(flag, short, true, rest[1:])
+			}
+			if _, err := f.parseValue(flag, short, false, ""); err != nil {
+				return false, err
+			}
+			continue
+		}
+		if len(rest) > 0 {
+			return f.parseValue(flag, short, true, strings.TrimPrefix(rest, "="))
+		}
+		return f.parseValue(flag, short, false, "")
+	}
+	return true, nil
+}
+
+func (f *FlagSet) parseValue(flag *Flag, name string, hasValue bool, value string) (bool, error) {
 	if fv, ok := flag.Value.(boolFlag); ok && fv.IsBoolFlag() { // special case: doesn't need an arg
 		if hasValue {
 			if err := fv.Set(value); err != nil {
-				return false, failFmt(f, "invalid boolean value %q for -%s: %v", value, name, err)
+				return false, failErr(f, &InvalidValueError{name, value, "", err})
 			}
 		} else {
 			if err := fv.Set("true"); err != nil {
-				return false, failFmt(f, "invalid boolean flag %s: %v", name, err)
+				return false, failErr(f, &InvalidValueError{name, "true", "", err})
 			}
 		}
 	} else {
@@ -612,17 +785,48 @@ func (f *FlagSet) parseOne() (bool, error) {
 			value, f.args = f.args[0], f.args[1:]
 		}
 		if !hasValue {
-			return false, failFmt(f, "flag needs an argument: -%s", name)
+			return false, failErr(f, &MissingValueError{name})
 		}
 		if err := flag.Value.Set(value); err != nil {
-			return false, failFmt(f, "invalid value %q for flag -%s: %v", value, name, err)
+			return false, failErr(f, &InvalidValueError{name, value, "", err})
 		}
 	}
+	f.mark(flag)
+	return true, nil
+}
+
+// marks the flag as set, and as set by the current parse
+func (f *FlagSet) mark(flag *Flag) {
 	if f.actual == nil {
 		f.actual = make(map[string]*Flag)
 	}
-	f.actual[name] = flag
-	return true, nil
+	f.actual[flag.Name] = flag
+	if f.current == nil {
+		f.current = make(map[string]*Flag)
+	}
+	f.current[flag.Name] = flag
+}
+
+// sets any flag not found on the command line of the current parse from the
+// first of its environment variables that is present
+func (f *FlagSet) parseEnv() error {
+	for _, flag := range sortFlags(f.formal) {
+		if _, ok := f.current[flag.Name]; ok {
+			continue
+		}
+		for _, key := range f.EnvNames(flag.Name) {
+			value, ok := os.LookupEnv(key)
+			if !ok {
+				continue
+			}
+			if err := flag.Value.Set(value); err != nil {
+				return failErr(f, &InvalidValueError{flag.Name, value, key, err})
+			}
+			f.mark(flag)
+			break
+		}
+	}
+	return nil
 }
 
 //  *FlagSet function satisfying the Parser interface Parsed function.
@@ -688,7 +892,10 @@ func sortFlags(flags map[string]*Flag) []*Flag {
 // This will panic for duplicate and/or  previously defined Flags.
 func (f *FlagSet) Var(value Value, name string, usage string) {
 	// Remember the default value as a string; it won't change.
-	flag := &Flag{name, usage, value, value.String()}
+	flag := &Flag{name, usage, value, value.String(), "", nil, sliceElements(value)}
+	if r, ok := value.(restorer); ok {
+		r.record()
+	}
 	_, alreadythere := f.formal[name]
 	if alreadythere {
 		msg := fmt.Sprintf("%s flag redefined: %s", f.name, name)
@@ -701,6 +908,73 @@ func (f *FlagSet) Var(value Value, name string, usage string) {
 	f.formal[name] = flag
 }
 
+// Sets a one letter short name for the previously defined flag of the provided
+// name, so that -v is equivalent to -verbose or --verbose. Boolean short flags
+// may be bundled (-abc), and a short flag taking a value may have it attached
+// (-ofile). This will panic for an undefined flag, an invalid short name, or a
+// short name already in use.
+func (f *FlagSet) SetShort(name, short string) {
+	flag, ok := f.formal[name]
+	var msg string
+	switch {
+	case !ok:
+		msg = fmt.Sprintf("%s flag not defined for short name: %s", f.name, name)
+	case len(short) != 1 || short == "-" || short == "=":
+		msg = fmt.Sprintf("%s flag %s has invalid short name: %q", f.name, name, short)
+	case f.shorts[short] != nil || (f.formal[short] != nil && f.formal[short] != flag):
+		msg = fmt.Sprintf("%s flag short name redefined: %s", f.name, short)
+	}
+	if msg != "" {
+		fmt.Fprintln(f.Out(), msg)
+		panic(msg)
+	}
+	if f.shorts == nil {
+		f.shorts = make(map[string]*Flag)
+	}
+	if flag.Short != "" {
+		delete(f.shorts, flag.Short)
+	}
+	flag.Short = short
+	f.shorts[short] = flag
+}
+
+// Sets environment variable names consulted, in order, for the previously
+// defined flag of the provided name when it is not given on the command line.
+// Command line values take precedence over environment values, which take
+// precedence over the flag default. This will panic for an undefined flag.
+func (f *FlagSet) SetEnv(name string, keys ...string) {
+	flag, ok := f.formal[name]
+	if !ok {
+		msg := fmt.Sprintf("%s flag not defined for environment variable: %s", f.name, name)
+		fmt.Fprintln(f.Out(), msg)
+		panic(msg)
+	}
+	flag.EnvVars = append(flag.EnvVars, keys...)
+}
+
+// Sets a prefix (e.g. "MYTOOL_") so that every flag of the *FlagSet falls back
+// to an environment variable of the prefix and the upper cased flag name, with
+// '-' and '.' replaced by '_' (e.g. MYTOOL_LOG_LEVEL for -log-level), after any
+// environment variables set by SetEnv.
+func (f *FlagSet) SetEnvPrefix(prefix string) {
+	f.envPrefix = prefix
+}
+
+// Returns the environment variable names consulted for the flag of the
+// provided name, in order of precedence.
+func (f *FlagSet) EnvNames(name string) []string {
+	flag := f.Lookup(name)
+	if flag == nil {
+		return nil
+	}
+	ret := append([]string{}, flag.EnvVars...)
+	if f.envPrefix != "" {
+		r := strings.NewReplacer("-", "_", ".", "_")
+		ret = append(ret, f.envPrefix+strings.ToUpper(r.Replace(flag.Name)))
+	}
+	return ret
+}
+
 //
 func (f *FlagSet) BoolVar(p *bool, name string, value bool, usage string) {
 	f.Var(newBoolValue(value, p), name, usage)
@@ -892,7 +1166,7 @@ func (f *FlagSet) RegexVar(name, usage string, xfn RgxBridgeFunc, rawRegexps ...
 
 // A contain backed flag processing a regular expression
 func (f *FlagSet) RegexContainVar(d StringContain, name, key, usage string, xfn RgxContainBridgeFunc, rawRegexps ...string) StringContain {
-	f.Var(regexContainValue(key, xfn, d, rawRegexps...), name, usage)
+	f.Var(newRegexContainValue(key, xfn, d, rawRegexps...), name, usage)
 	return d
 }
 
@@ -929,31 +1203,52 @@ type Wuser interface {
 //
 func (f *FlagSet) Usage(o io.Writer) {
 	f.VisitAll(func(flag *Flag) {
-		s := fmt.Sprintf("\t-%s", flag.Name) // Two spaces before -; see next two comments.
-		name, usage := UnquoteMessage(flag)
-		if len(name) > 0 {
-			s += " " + name
+		white(o, flagLine(flag, f.EnvNames(flag.Name), f.IsRequired(flag.Name)), "\n")
+	})
+	f.constraintUsage(o)
+}
+
+// returns the usage line of a flag, given its environment variables and if
+// it is required
+func flagLine(flag *Flag, env []string, required bool) string {
+	s := fmt.Sprintf("\t%s", flag.Names()) // Two spaces before -; see next two comments.
+	name, usage := UnquoteMessage(flag)
+	if len(name) > 0 {
+		s += " " + name
+	}
+	// Boolean flags of one ASCII letter are so common we
+	// treat them specially, putting their usage on the same line.
+	if len(s) <= 4 { // space, space, '-', 'x'.
+		s += "\t"
+	} else {
+		// Four spaces before the tab triggers good alignment
+		// for both 4- and 8-space tab stops.
+		s += "\n    \t"
+	}
+	s += fmt.Sprintf("\t%s", usage)
+	if !isZeroValue(flag.DefValue) {
+		switch flag.Value.(type) {
+		case *stringValue, *enumValue:
+			// put quotes on the value
+			s += fmt.Sprintf(" (default %q)", flag.DefValue)
+		default:
+			s += fmt.Sprintf(" (default %v)", flag.DefValue)
 		}
-		// Boolean flags of one ASCII letter are so common we
-		// treat them specially, putting their usage on the same line.
-		if len(s) <= 4 { // space, space, '-', 'x'.
-			s += "\t"
+	}
+	if len(env) > 0 {
+		s += fmt.Sprintf(" (env $%s)", strings.Join(env, ", $"))
+	}
+	if sv, ok := flag.Value.(*sliceValue); ok {
+		if sv.sep != "" {
+			s += fmt.Sprintf(" (repeatable, separated by %q)", sv.sep)
 		} else {
-			// Four spaces before the tab triggers good alignment
-			// for both 4- and 8-space tab stops.
-			s += "\n    \t"
+			s += " (repeatable)"
 		}
-		s += fmt.Sprintf("\t%s", usage)
-		if !isZeroValue(flag.DefValue) {
-			if _, ok := flag.Value.(*stringValue); ok {
-				// put quotes on the value
-				s += fmt.Sprintf(" (default %q)", flag.DefValue)
-			} else {
-				s += fmt.Sprintf(" (default %v)", flag.DefValue)
-			}
-		}
-		white(o, s, "\n")
-	})
+	}
+	if required {
+		s += " (required)"
+	}
+	return s
 }
 
 //
@@ -988,28 +1283,42 @@ func UnquoteMessage(flag *Flag) (name string, usage string) {
 	case *uintValue, *uint64Value:
 		name = "uint"
 	case *containValue:
-		vv := flag.Value.(*containValue)
-		switch vv.kind {
-		case "bool":
-			name = ""
-		case "duration":
-			name = "duration"
-		case "float64":
-			name = "float"
-		case "int", "int64":
-			name = "int"
-		case "string", "regex":
-			name = "string"
-		case "uint", "uint64":
-			name = "uint"
-		}
+		name = kindName(flag.Value.(*containValue).kind, name)
+	case *regexContainValue:
+		name = kindName(flag.Value.(*regexContainValue).kind, name)
+	case *sliceValue:
+		name = kindName(flag.Value.(*sliceValue).kind, name)
+	case Chooser:
+		name = strings.Join(flag.Value.(Chooser).Choices(), "|")
+	case TypeNamer:
+		name = flag.Value.(TypeNamer).TypeName()
 	}
 	return
 }
 
+func kindName(kind, name string) string {
+	switch kind {
+	case "bool":
+		name = ""
+	case "duration":
+		name = "duration"
+	case "float64":
+		name = "float"
+	case "int", "int64":
+		name = "int"
+	case "string", "regex":
+		name = "string"
+	case "uint", "uint64":
+		name = "uint"
+	case "map":
+		name = "key=value"
+	}
+	return name
+}
+
 func isZeroValue(value string) bool {
 	switch value {
-	case "false", "", "0", "0s":
+	case "false", "", "0", "0s", "[]":
 		return true
 	}
 	return false
@@ -2,9 +2,15 @@ package flip
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -342,3 +348,572 @@ func fsMethod(t *testing.T, catch bool, fs *FlagSet, m string, in ...interface{}
 	}
 	method.Call(params)
 }
+
+type shortFlags struct {
+	all, bare, check bool
+	out              string
+	n                int
+}
+
+func shortFlagSet(sf *shortFlags, b *bytes.Buffer) *FlagSet {
+	fs := NewFlagSet("short", ContinueOnError)
+	fs.SetOut(b)
+	fs.BoolVar(&sf.all, "all", false, "A boolean flag")
+	fs.SetShort("all", "a")
+	fs.BoolVar(&sf.bare, "bare", false, "A boolean flag")
+	fs.SetShort("bare", "b")
+	fs.BoolVar(&sf.check, "check", false, "A boolean flag without a short name")
+	fs.StringVar(&sf.out, "output", "", "A string flag")
+	fs.SetShort("output", "o")
+	fs.IntVar(&sf.n, "number", 0, "An integer flag")
+	fs.SetShort("number", "n")
+	return fs
+}
+
+var shortExpect = []struct {
+	args  []string
+	exp   shortFlags
+	rest  []string
+	catch bool
+}{
+	{[]string{"-a"}, shortFlags{all: true}, nil, false},
+	{[]string{"--all", "-all"}, shortFlags{all: true}, nil, false},
+	{[]string{"-ab", "x"}, shortFlags{all: true, bare: true}, []string{"x"}, false},
+	{[]string{"-abo", "file"}, shortFlags{all: true, bare: true, out: "file"}, nil, false},
+	{[]string{"-aofile"}, shortFlags{all: true, out: "file"}, nil, false},
+	{[]string{"-o=file", "-n10"}, shortFlags{out: "file", n: 10}, nil, false},
+	{[]string{"-b", "-a=false", "--output", "file"}, shortFlags{bare: true, out: "file"}, nil, false},
+	{[]string{"-check"}, shortFlags{check: true}, nil, false},
+	{[]string{"-ac"}, shortFlags{all: true}, nil, true},
+	{[]string{"--a"}, shortFlags{}, nil, true},
+	{[]string{"-bn"}, shortFlags{bare: true}, nil, true},
+	{[]string{"-nx"}, shortFlags{}, nil, true},
+}
+
+func TestShortFlag(t *testing.T) {
+	for _, x := range shortExpect {
+		sf := &shortFlags{}
+		b := new(bytes.Buffer)
+		fs := shortFlagSet(sf, b)
+		err := fs.Parse(x.args)
+		if (err != nil) != x.catch {
+			t.Errorf("%v: expected error %t, got %v", x.args, x.catch, err)
+		}
+		if *sf != x.exp {
+			t.Errorf("%v: expected %+v, got %+v", x.args, x.exp, *sf)
+		}
+		if !x.catch && !reflect.DeepEqual(fs.Args(), x.rest) && len(fs.Args())+len(x.rest) > 0 {
+			t.Errorf("%v: expected remaining %v, got %v", x.args, x.rest, fs.Args())
+		}
+	}
+
+	b := new(bytes.Buffer)
+	fs := shortFlagSet(&shortFlags{}, b)
+	if fs.Lookup("o") != fs.Lookup("output") {
+		t.Error("Lookup by short name did not return the long flag")
+	}
+	fs.Usage(b)
+	usg := b.String()
+	for _, u := range []string{"-a, --all", "-o, --output string", "-check"} {
+		if !strings.Contains(usg, u) {
+			t.Errorf("short flag usage error: expected %s in %s", u, usg)
+		}
+	}
+
+	for _, s := range []string{"a", "oo", "-"} {
+		func() {
+			defer catchShouldPanic(t, "SetShort "+s, b)
+			fs.SetShort("check", s)
+		}()
+	}
+}
+
+func TestEnvFlag(t *testing.T) {
+	var s1, s2, s3 string
+	var i int
+	b := new(bytes.Buffer)
+	fs := NewFlagSet("env", ContinueOnError)
+	fs.SetOut(b)
+	fs.SetEnvPrefix("FLIPTEST_")
+	fs.StringVar(&s1, "first", "def1", "A string flag")
+	fs.SetEnv("first", "FLIPTEST_ONE", "FLIPTEST_ALT")
+	fs.StringVar(&s2, "second-flag", "def2", "A string flag")
+	fs.StringVar(&s3, "third", "def3", "A string flag")
+	fs.IntVar(&i, "int", 0, "An integer flag")
+
+	t.Setenv("FLIPTEST_ALT", "alt")
+	t.Setenv("FLIPTEST_FIRST", "prefixed")
+	t.Setenv("FLIPTEST_SECOND_FLAG", "env2")
+	t.Setenv("FLIPTEST_THIRD", "env3")
+	if err := fs.Parse([]string{"-third", "argv3"}); err != nil {
+		t.Fatalf("unexpected parse error: %s", err)
+	}
+	if s1 != "alt" || s2 != "env2" || s3 != "argv3" || i != 0 {
+		t.Errorf("environment flag error: got %q %q %q %d", s1, s2, s3, i)
+	}
+	if fs.NFlag() != 3 {
+		t.Errorf("environment flag error: expected 3 set flags, got %d", fs.NFlag())
+	}
+
+	fs.Usage(b)
+	usg := b.String()
+	for _, u := range []string{"(env $FLIPTEST_ONE, $FLIPTEST_ALT, $FLIPTEST_FIRST)", "(env $FLIPTEST_SECOND_FLAG)"} {
+		if !strings.Contains(usg, u) {
+			t.Errorf("environment flag usage error: expected %s in %s", u, usg)
+		}
+	}
+
+	if err := fs.Parse([]string{"-first", "argv1"}); err != nil || s1 != "argv1" {
+		t.Fatalf("unexpected parse %q, %v", s1, err)
+	}
+	if err := fs.Parse(nil); err != nil || s1 != "alt" || s3 != "env3" {
+		t.Errorf("environment flag error: expected fallback on reparse, got %q %q, %v", s1, s3, err)
+	}
+
+	t.Setenv("FLIPTEST_INT", "red")
+	fs = NewFlagSet("env", ContinueOnError)
+	fs.SetOut(b)
+	fs.SetEnvPrefix("FLIPTEST_")
+	fs.IntVar(&i, "int", 0, "An integer flag")
+	if err := fs.Parse(nil); err == nil {
+		t.Error("expected error for invalid environment value")
+	}
+}
+
+func constraintFlagSet(b *bytes.Buffer) *FlagSet {
+	fs := NewFlagSet("constraint", ContinueOnError)
+	fs.SetOut(b)
+	fs.String("name", "", "A required flag")
+	fs.Bool("json", false, "An exclusive flag")
+	fs.Bool("yaml", false, "An exclusive flag")
+	fs.String("tls-key", "", "A flag requiring tls-cert")
+	fs.String("tls-cert", "", "A flag required by tls-key")
+	fs.String("id", "", "One of id or label required")
+	fs.String("label", "", "One of id or label required")
+	fs.SetRequired("name")
+	fs.SetExclusive("json", "yaml")
+	fs.SetRequires("tls-key", "tls-cert")
+	fs.SetOneRequired("id", "label")
+	return fs
+}
+
+var constraintExpect = []struct {
+	args      []string
+	kind      ConstraintKind
+	offending []string
+}{
+	{[]string{"-name", "n", "-id", "1"}, -1, nil},
+	{[]string{"-id", "1"}, Required, []string{"name"}},
+	{[]string{"-name", "n", "-label", "l", "-json", "-yaml"}, Exclusive, []string{"json", "yaml"}},
+	{[]string{"-name", "n", "-label", "l", "-tls-key", "k"}, Requires, []string{"tls-cert"}},
+	{[]string{"-name", "n", "-label", "l", "-tls-key", "k", "-tls-cert", "c"}, -1, nil},
+	{[]string{"-name", "n"}, OneRequired, []string{"id", "label"}},
+}
+
+func TestConstraint(t *testing.T) {
+	for _, x := range constraintExpect {
+		b := new(bytes.Buffer)
+		fs := constraintFlagSet(b)
+		err := fs.Parse(x.args)
+		if x.kind < 0 {
+			if err != nil {
+				t.Errorf("%v: unexpected error %s", x.args, err)
+			}
+			continue
+		}
+		ce, ok := err.(*ConstraintError)
+		if !ok {
+			t.Errorf("%v: expected *ConstraintError, got %v", x.args, err)
+			continue
+		}
+		if ce.Kind != x.kind || !reflect.DeepEqual(ce.Offending, x.offending) {
+			t.Errorf("%v: expected %s %v, got %s %v", x.args, x.kind, x.offending, ce.Kind, ce.Offending)
+		}
+	}
+
+	b := new(bytes.Buffer)
+	fs := constraintFlagSet(b)
+	fs.Usage(b)
+	usg := b.String()
+	for _, u := range []string{
+		"A required flag (required)",
+		"[-json | -yaml] mutually exclusive",
+		"-tls-key requires -tls-cert",
+		"[-id | -label] at least one required",
+	} {
+		if !strings.Contains(usg, u) {
+			t.Errorf("constraint usage error: expected %s in %s", u, usg)
+		}
+	}
+
+	func() {
+		defer catchShouldPanic(t, "SetRequired undefined", b)
+		fs.SetRequired("undefined")
+	}()
+}
+
+func TestConstraintReuse(t *testing.T) {
+	b := new(bytes.Buffer)
+	f := New("test")
+	f.SetOut(b)
+	f.SetGroup("run", 1, NewCommand("", "run", "run command", 1, false,
+		func(c context.Context, a []string) (context.Context, ExitStatus) { return c, ExitSuccess },
+		constraintFlagSet(b)))
+	for _, x := range []struct {
+		args   []string
+		expect int
+	}{
+		{[]string{"test", "run", "-name", "x", "-id", "1", "-json"}, 0},
+		{[]string{"test", "run", "-name", "x", "-id", "1", "-yaml"}, 0},
+		{[]string{"test", "run", "-id", "1"}, -2},
+	} {
+		if res := f.Execute(context.Background(), x.args); res != x.expect {
+			t.Errorf("%v: expected exit %d, got %d", x.args, x.expect, res)
+		}
+	}
+}
+
+type sliceContain map[string][]string
+
+func (c sliceContain) SetStrings(k string, v []string) { c[k] = v }
+func (c sliceContain) ToStrings(k string) []string     { return c[k] }
+
+func TestSliceFlag(t *testing.T) {
+	var ss, dflt []string
+	var is []int
+	var ds []time.Duration
+	b := new(bytes.Buffer)
+	c := make(sliceContain)
+	fs := NewFlagSet("slice", ContinueOnError)
+	fs.SetOut(b)
+	fs.StringSliceVar(&ss, "include", nil, "A repeatable string flag")
+	fs.StringSliceVar(&dflt, "default", []string{"x", "y"}, "A repeatable string flag with default")
+	fs.IntSliceVar(&is, "n", nil, "A repeatable int flag")
+	fs.SetSeparator("n", ",")
+	fs.DurationSliceVar(&ds, "d", nil, "A repeatable duration flag")
+	fs.StringSliceContainVar(c, "sv", "svKey", []string{"c"}, "A contain backed repeatable string flag")
+	err := fs.Parse([]string{
+		"-include", "a", "-include", "b,c",
+		"-default", "z",
+		"-n", "1,2", "-n", "3",
+		"-d", "1s", "-d", "2m",
+		"-sv", "d", "-sv", "e",
+	})
+	if err != nil {
+		t.Fatalf("unexpected slice parse error: %s", err)
+	}
+	for _, x := range []struct {
+		have, exp interface{}
+	}{
+		{ss, []string{"a", "b,c"}},
+		{dflt, []string{"z"}},
+		{is, []int{1, 2, 3}},
+		{ds, []time.Duration{time.Second, 2 * time.Minute}},
+		{c["svKey"], []string{"d", "e"}},
+		{fs.Lookup("sv").Value.Get(), []string{"d", "e"}},
+	} {
+		if !reflect.DeepEqual(x.have, x.exp) {
+			t.Errorf("slice flag error: expected %v, got %v", x.exp, x.have)
+		}
+	}
+
+	fs.Usage(b)
+	usg := b.String()
+	for _, u := range []string{
+		"-include string",
+		"A repeatable string flag (repeatable)",
+		`(default [x y]) (repeatable)`,
+		`-n int`,
+		`(repeatable, separated by ",")`,
+	} {
+		if !strings.Contains(usg, u) {
+			t.Errorf("slice flag usage error: expected %s in %s", u, usg)
+		}
+	}
+
+	if err := fs.SetDefault("include", "p", "q"); err != nil || !reflect.DeepEqual(ss, []string{"p", "q"}) {
+		t.Errorf("slice flag default error: %v %v", err, ss)
+	}
+	fs.Set("include", "r")
+	if !reflect.DeepEqual(ss, []string{"r"}) {
+		t.Errorf("slice flag setting should replace default, got %v", ss)
+	}
+	if err := fs.Parse([]string{"-n", "1,x"}); err == nil {
+		t.Error("expected error for invalid slice element")
+	}
+}
+
+type mapContain map[string]map[string]string
+
+func (c mapContain) SetStringMap(k string, v map[string]string) { c[k] = v }
+func (c mapContain) ToStringMap(k string) map[string]string     { return c[k] }
+
+func TestMapFlag(t *testing.T) {
+	var labels map[string]string
+	b := new(bytes.Buffer)
+	c := make(mapContain)
+	fs := NewFlagSet("map", ContinueOnError)
+	fs.SetOut(b)
+	fs.StringMapVar(&labels, "label", map[string]string{"a": "b"}, "A key=value flag")
+	fs.MapContainVar(c, "mv", "mvKey", nil, "A contain backed key=value flag")
+	fs.SetSeparator("mv", ",")
+	err := fs.Parse([]string{"-label", "env=prod", "-label", "team=infra", "-label", "eq=x=y", "-mv", "a=1,b=2", "-mv", "a=3"})
+	if err != nil {
+		t.Fatalf("unexpected map parse error: %s", err)
+	}
+	if exp := map[string]string{"env": "prod", "team": "infra", "eq": "x=y"}; !reflect.DeepEqual(labels, exp) {
+		t.Errorf("map flag error: expected %v, got %v", exp, labels)
+	}
+	if exp := map[string]string{"a": "3", "b": "2"}; !reflect.DeepEqual(c["mvKey"], exp) {
+		t.Errorf("map flag error: expected %v, got %v", exp, c["mvKey"])
+	}
+
+	fs.Usage(b)
+	usg := b.String()
+	for _, u := range []string{"-label key=value", "(default [a=b]) (repeatable)", "-mv key=value"} {
+		if !strings.Contains(usg, u) {
+			t.Errorf("map flag usage error: expected %s in %s", u, usg)
+		}
+	}
+
+	for _, bad := range []string{"novalue", "=value"} {
+		b.Reset()
+		err := fs.Parse([]string{"-label", bad})
+		if err == nil || !strings.Contains(err.Error(), "expected key=value pair") {
+			t.Errorf("expected malformed pair error for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestEnumFlag(t *testing.T) {
+	var format string
+	b := new(bytes.Buffer)
+	_, itm := lal.NewItem("test_enum")
+	fs := NewFlagSet("enum", ContinueOnError)
+	fs.SetOut(b)
+	fs.EnumVar(&format, "format", "json", []string{"json", "yaml", "text"}, "An enum flag")
+	fs.EnumContainVar(itm, "level", "levelKey", "info", []string{"debug", "info"}, "A contain backed enum flag")
+	fs.SetCaseInsensitive("level")
+	if err := fs.Parse([]string{"-format", "yaml", "-level", "DEBUG"}); err != nil {
+		t.Fatalf("unexpected enum parse error: %s", err)
+	}
+	if format != "yaml" || itm.ToString("levelKey") != "debug" {
+		t.Errorf("enum flag error: got %q %q", format, itm.ToString("levelKey"))
+	}
+	err := fs.Parse([]string{"-format", "YAML"})
+	if err == nil || !strings.Contains(err.Error(), "must be one of json, yaml, text") {
+		t.Errorf("expected enum choice error, got %v", err)
+	}
+	if format != "yaml" {
+		t.Errorf("enum flag error: invalid value should not be set, got %q", format)
+	}
+
+	b.Reset()
+	fs.Usage(b)
+	usg := b.String()
+	for _, u := range []string{"-format json|yaml|text", `(default "json")`, "-level debug|info"} {
+		if !strings.Contains(usg, u) {
+			t.Errorf("enum flag usage error: expected %s in %s", u, usg)
+		}
+	}
+
+	func() {
+		defer catchShouldPanic(t, "SetCaseInsensitive non enum", b)
+		fs.String("s", "", "not an enum")
+		fs.SetCaseInsensitive("s")
+	}()
+}
+
+type level int
+
+func TestTypedFlag(t *testing.T) {
+	var ip net.IP
+	var prefix netip.Prefix
+	var u url.URL
+	var lvl level
+	var on bool
+	var upper string
+	when := new(time.Time)
+	n := new(big.Int)
+	b := new(bytes.Buffer)
+	fs := NewFlagSet("typed", ContinueOnError)
+	fs.SetOut(b)
+	TypedVar(fs, &ip, "ip", net.ParseIP("127.0.0.1"), nil, "An ip flag")
+	TypedVar(fs, &prefix, "prefix", netip.Prefix{}, nil, "A prefix flag")
+	TypedVar(fs, &u, "url", url.URL{}, nil, "A url flag")
+	TypedVar(fs, &lvl, "level", 1, nil, "A named int flag")
+	TypedVar(fs, &on, "on", false, nil, "A boolean flag")
+	TypedVar(fs, &upper, "upper", "", func(s string) (string, error) { return strings.ToUpper(s), nil }, "A custom flag")
+	d := Typed(fs, "wait", time.Second, nil, "A duration flag")
+	fs.TextVar(when, "when", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), "A time flag")
+	fs.TextVar(n, "big", big.NewInt(7), "A big integer flag")
+
+	err := fs.Parse([]string{
+		"-ip", "10.0.0.1", "-prefix", "10.0.0.0/8", "-url", "https://example.com/x",
+		"-level", "3", "-on", "-upper", "abc", "-wait", "2m",
+		"-when", "2021-02-03T04:05:06Z", "-big", "123456789012345678901234567890",
+	})
+	if err != nil {
+		t.Fatalf("unexpected typed parse error: %s", err)
+	}
+	for _, x := range []struct {
+		have, exp string
+	}{
+		{ip.String(), "10.0.0.1"},
+		{prefix.String(), "10.0.0.0/8"},
+		{u.Host, "example.com"},
+		{fmt.Sprint(lvl), "3"},
+		{fmt.Sprint(on), "true"},
+		{upper, "ABC"},
+		{d.String(), "2m0s"},
+		{when.Format(time.RFC3339), "2021-02-03T04:05:06Z"},
+		{n.String(), "123456789012345678901234567890"},
+	} {
+		if x.have != x.exp {
+			t.Errorf("typed flag error: expected %s, got %s", x.exp, x.have)
+		}
+	}
+
+	fs.Usage(b)
+	usg := b.String()
+	for _, u := range []string{
+		"-ip ip", "(default 127.0.0.1)", "-prefix prefix", "-url url", "-level int",
+		"-upper string", "-wait duration", "-when time", "(default 2020-01-01T00:00:00Z)", "-big int", "(default 7)",
+	} {
+		if !strings.Contains(usg, u) {
+			t.Errorf("typed flag usage error: expected %s in %s", u, usg)
+		}
+	}
+
+	if err := fs.Parse([]string{"-ip", "not-an-ip"}); err == nil {
+		t.Error("expected error for invalid typed value")
+	}
+	func() {
+		defer catchShouldPanic(t, "TypedVar without parse", b)
+		var c chan int
+		TypedVar(fs, &c, "chan", nil, nil, "An unsupported flag")
+	}()
+}
+
+func TestResetFlag(t *testing.T) {
+	var (
+		str, format string
+		num         int
+		on          bool
+		wait        time.Duration
+		tags        []string
+		labels      map[string]string
+		ip          net.IP
+	)
+	b := new(bytes.Buffer)
+	fs := NewFlagSet("reset", ContinueOnError)
+	fs.SetOut(b)
+	fs.StringVar(&str, "str", "def", "A string flag")
+	fs.IntVar(&num, "num", 3, "An int flag")
+	fs.BoolVar(&on, "on", false, "A boolean flag")
+	fs.SetShort("on", "o")
+	fs.DurationVar(&wait, "wait", time.Second, "A duration flag")
+	fs.StringSliceVar(&tags, "tag", []string{"a", "b"}, "A slice flag")
+	fs.StringMapVar(&labels, "label", map[string]string{"k": "v"}, "A map flag")
+	fs.EnumVar(&format, "format", "", []string{"json", "yaml"}, "An enum flag")
+	TypedVar(fs, &ip, "ip", net.ParseIP("127.0.0.1"), nil, "An ip flag")
+	fs.SetRequired("num")
+	if err := fs.SetDefault("wait", "2s"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := fs.Parse([]string{
+		"-str", "x", "-num", "4", "-o", "-wait", "5s", "-tag", "c", "-label", "x=y",
+		"-format", "yaml", "-ip", "10.0.0.1", "rest",
+	})
+	if err != nil {
+		t.Fatalf("unexpected parse error: %s", err)
+	}
+	if err := fs.Reset(); err != nil {
+		t.Fatalf("unexpected reset error: %s", err)
+	}
+	if str != "def" || num != 3 || on || wait != 2*time.Second || format != "" || ip.String() != "127.0.0.1" ||
+		!reflect.DeepEqual(tags, []string{"a", "b"}) || !reflect.DeepEqual(labels, map[string]string{"k": "v"}) {
+		t.Errorf("flags not reset: %q %d %v %v %v %q %v %v", str, num, on, wait, tags, format, ip, labels)
+	}
+	if fs.Parsed() || fs.NArg() != 0 || fs.Lookup("num") == nil {
+		t.Error("parse state not reset")
+	}
+	var set int
+	fs.Visit(func(*Flag) { set++ })
+	if set != 0 {
+		t.Errorf("expected no flags set after reset, got %d", set)
+	}
+
+	if err := fs.Parse([]string{"-tag", "d"}); err == nil {
+		t.Error("expected required flag error after reset")
+	}
+	if !reflect.DeepEqual(tags, []string{"d"}) {
+		t.Errorf("expected reset slice replaced by first value, got %v", tags)
+	}
+}
+
+type stringContain map[string]string
+
+func (c stringContain) SetString(k, v string)    { c[k] = v }
+func (c stringContain) ToString(k string) string { return c[k] }
+
+func TestResetRegexTypedFlag(t *testing.T) {
+	var matched string
+	var calls int
+	bridge := func(s string, rs ...*regexp.Regexp) error {
+		calls++
+		for _, r := range rs {
+			if !r.MatchString(s) {
+				return fmt.Errorf("no match")
+			}
+		}
+		matched = s
+		return nil
+	}
+	sc := stringContain{}
+	var level int
+	b := new(bytes.Buffer)
+	fs := NewFlagSet("reset", ContinueOnError)
+	fs.SetOut(b)
+	fs.RegexVar("r", "A regex flag", bridge, "^adam[0-9]$")
+	fs.RegexContainVar(sc, "rv", "rvKey", "A contain backed regex flag",
+		func(s string, v StringContain, rs ...*regexp.Regexp) error {
+			for _, r := range rs {
+				if !r.MatchString(s) {
+					return fmt.Errorf("no match")
+				}
+			}
+			v.SetString("rvKey", s)
+			return nil
+		},
+		"^eve[0-9]$",
+	)
+	TypedVar(fs, &level, "level", 2, func(s string) (int, error) {
+		if !strings.HasPrefix(s, "level") {
+			return 0, fmt.Errorf("invalid level %q", s)
+		}
+		return strconv.Atoi(strings.TrimPrefix(s, "level"))
+	}, "A typed flag")
+
+	for i := 0; i < 2; i++ {
+		if err := fs.Parse([]string{"-r", "adam2", "-rv", "eve7", "-level", "level5"}); err != nil {
+			t.Fatalf("unexpected parse error: %s", err)
+		}
+		if matched != "adam2" || sc["rvKey"] != "eve7" || level != 5 {
+			t.Errorf("unexpected values %q %q %d", matched, sc["rvKey"], level)
+		}
+		if err := fs.Reset(); err != nil {
+			t.Fatalf("unexpected reset error: %s", err)
+		}
+		if sc["rvKey"] != "^eve[0-9]$" || level != 2 {
+			t.Errorf("flags not reset: %q %d", sc["rvKey"], level)
+		}
+		if matched != "adam2" {
+			t.Errorf("expected the regex flag value kept by its bridge function, got %q", matched)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected the bridge function called by parsing only, got %d calls", calls)
+	}
+}
@@ -15,22 +15,31 @@ type Flipper interface {
 	Commander
 	Executer
 	Cleaner
+	ExitCoder
 }
 
 type flipper struct {
+	name string
 	Commander
-	Instructer
-	*executer //Executer
-	*cleaner  //Cleaner
+	*iswapper   //Instructer
+	*executer   //Executer
+	*cleaner    //Cleaner
+	*configurer //Configurer
+	*exitCoder  //ExitCoder
 }
 
 // Return a new package default Flipper corresponding to the provided string name.
 func New(name string) *flipper {
 	return newFlipper(
+		func(f *flipper) { f.name = name },
 		func(f *flipper) { f.cleaner = newCleaner() },
 		func(f *flipper) { f.Commander = newCommander(f) },
-		func(f *flipper) { f.Instructer = newInstructer(name, f.Commander, os.Stdout) },
-		func(f *flipper) { f.executer = newExecuter(f.Commander, f.RunCleanup) },
+		func(f *flipper) { f.iswapper = newInstructer(name, f.Commander, os.Stdout) },
+		func(f *flipper) { f.configurer = newConfigurer(name, f.Commander) },
+		func(f *flipper) { f.exitCoder = newExitCoder() },
+		func(f *flipper) {
+			f.executer = newExecuter(name, f.Commander, f.RunCleanupE, f.configurer.apply, f.ExitCode)
+		},
 		func(f *flipper) {
 			var ifn Cleanup
 			ifn = f.Instruction
@@ -54,12 +63,24 @@ func newFlipper(fns ...config) *flipper {
 // Currently, commands added by this method are:
 // - help (takes no other arguments)
 // - version (followed by package, tag, version, and hash information strings, in that order)
+// - completion (takes no other arguments, also adding a hidden __complete command)
+// - docs (takes no other arguments, writing man pages or Markdown of all commands)
+// - schema (takes no other arguments, printing a JSON description of all commands)
+// - shell (takes no other arguments, executing command lines read from standard input)
 func (f *flipper) AddBuiltIn(nc string, args ...string) *flipper {
 	switch nc {
 	case "help":
 		return f.addHelp()
 	case "version":
 		return f.addVersion(args...)
+	case "completion":
+		return f.addCompletion()
+	case "docs":
+		return f.addDocs()
+	case "schema":
+		return f.addSchema()
+	case "shell":
+		return f.addShell()
 	}
 	return f
 }
@@ -113,7 +134,9 @@ func (c *commander) SetGroup(name string, priority int, cmds ...Command) Flipper
 }
 
 // TODO: remove/control for duplicates
-// Get commands corresponding to the provided string keys.
+// Get commands corresponding to the provided string keys. A key may be a group
+// name, a command tag, or a space delimited command path (e.g. "remote add")
+// for commands nested beneath other commands.
 func (c *commander) GetCommand(ks ...string) []Command {
 	var ret []Command
 	for _, g := range c.groups.Has {
@@ -123,22 +146,38 @@ func (c *commander) GetCommand(ks ...string) []Command {
 			}
 		}
 		for _, cmd := range g.Commands {
-			for _, k := range ks {
-				if k == cmd.Tag() {
-					ret = append(ret, cmd)
-				}
-			}
+			ret = append(ret, getCommand(cmd, ks...)...)
 		}
 	}
 
 	return ret
 }
 
+func getCommand(cmd Command, ks ...string) []Command {
+	var ret []Command
+	for _, k := range ks {
+		if k == cmd.Tag() || k == cmd.Path() {
+			ret = append(ret, cmd)
+		}
+	}
+	for _, ch := range cmd.Children() {
+		ret = append(ret, getCommand(ch, ks...)...)
+	}
+	return ret
+}
+
 // Set the provided Commands, returning a Flip instance (useful for chaining).
 func (c *commander) SetCommand(cmds ...Command) Flipper {
+	var h *helper
+	if c.f.iswapper != nil {
+		h = c.f.helper()
+	}
 	for _, cmd := range cmds {
 		g := c.GetGroup(cmd.Group())
 		g.Commands = append(g.Commands, cmd)
+		if hs, ok := cmd.(interface{ setHelper(*helper) }); ok && h != nil {
+			hs.setHelper(h)
+		}
 	}
 	return c.f
 }
@@ -152,13 +191,24 @@ type Command interface {
 	Group() string
 	SetGroup(string)
 	Tag() string
+	Path() string
 	Priority() int
 	Escapes() bool
+	UseString() string
 	Use(io.Writer)
 	Execute(context.Context, []string) (context.Context, ExitStatus)
+	Subcommander
 	Flagger
 }
 
+// An interface for managing commands nested beneath a Command.
+type Subcommander interface {
+	Parent() Command
+	SetParent(Command)
+	Children() []Command
+	SetChild(...Command) Command
+}
+
 type command struct {
 	group, tag string
 	use        string
@@ -166,6 +216,10 @@ type command struct {
 	escapes    bool
 	hasRun     bool
 	cfn        CommandFunc
+	middleware []Middleware
+	parent     Command
+	children   []Command
+	helper     *helper
 	*FlagSet
 }
 
@@ -179,12 +233,16 @@ func NewCommand(group, tag, use string,
 	escapes bool,
 	cfn CommandFunc,
 	fs *FlagSet) Command {
-	return &command{group, tag, use, priority, escapes, false, cfn, fs}
+	return &command{group, tag, use, priority, escapes, false, cfn, nil, nil, nil, nil, fs}
 }
 
-// Set the Command group to the provided string.
+// Set the Command group, and the group of any child Commands, to the provided
+// string.
 func (c *command) SetGroup(k string) {
 	c.group = k
+	for _, ch := range c.children {
+		ch.SetGroup(k)
+	}
 }
 
 // Returns the Command group as a string.
@@ -197,6 +255,43 @@ func (c *command) Tag() string {
 	return c.tag
 }
 
+// Returns the space delimited tags of the Command and all its parents, e.g.
+// "remote add".
+func (c *command) Path() string {
+	if c.parent != nil {
+		return fmt.Sprintf("%s %s", c.parent.Path(), c.tag)
+	}
+	return c.tag
+}
+
+// Returns the Command this Command is nested beneath, or nil for a top level
+// Command.
+func (c *command) Parent() Command {
+	return c.parent
+}
+
+// Set the provided Command as the parent of this Command.
+func (c *command) SetParent(p Command) {
+	c.parent = p
+}
+
+// Returns the Commands nested beneath this Command.
+func (c *command) Children() []Command {
+	return c.children
+}
+
+// Nest the provided Commands beneath this Command, returning this Command
+// (useful for chaining). Child commands are only found in arguments following
+// their parent, share the parent group, and run after the parent.
+func (c *command) SetChild(cmds ...Command) Command {
+	for _, cmd := range cmds {
+		cmd.SetParent(c)
+		cmd.SetGroup(c.group)
+		c.children = append(c.children, cmd)
+	}
+	return c
+}
+
 // Returns the Command priority in its group as an integer.
 func (c *command) Priority() int {
 	return c.priority
@@ -207,28 +302,66 @@ func (c *command) Escapes() bool {
 	return c.escapes
 }
 
-func (c *command) useHead(o io.Writer) {
-	white(o, fmt.Sprintf("-----\n%s [<flags>]:\n", c.tag))
+// Returns the Command use string.
+func (c *command) UseString() string {
+	return c.use
 }
 
-func (c *command) useString(o io.Writer) {
-	white(o, fmt.Sprintf("\t%s\n\n", c.use))
+// Writes the Command's entire usage, followed by the usage of any child
+// Commands, to the provided io.Writer, by the command templates of the
+// Flipper the Command is set to (see SetCommandTemplate), or
+// DefaultCommandTemplate.
+func (c *command) Use(o io.Writer) {
+	c.templates().write(o, NewCommandHelp(c))
 }
 
-// Writes the Command's entire usage to the provided io.Writer.
-func (c *command) Use(o io.Writer) {
-	c.useHead(o)
-	c.useString(o)
-	c.Usage(o)
-	fmt.Fprint(o, "\n")
+func (c *command) setHelper(h *helper) {
+	c.helper = h
+}
+
+// returns the command templates of the Command, or of its closest parent with
+// any, or the defaults
+func (c *command) templates() *helper {
+	if c.helper != nil {
+		return c.helper
+	}
+	if p, ok := c.parent.(*command); ok {
+		return p.templates()
+	}
+	return defaultHelper
+}
+
+// Returns a boolean indicating if the Command CommandFunc has run since
+// Cleanup functions last ran.
+func (c *command) HasRun() bool {
+	return c.hasRun
 }
 
-// Executes the Commands CommandFunc.
+func (c *command) clearRun() {
+	c.hasRun = false
+}
+
+// Set the provided Middleware to wrap the Command CommandFunc, the first
+// provided outermost.
+func (c *command) SetMiddleware(mws ...Middleware) {
+	c.middleware = append(c.middleware, mws...)
+}
+
+// Executes the Commands CommandFunc, wrapped by any Command Middleware. A
+// Command without a CommandFunc that has child Commands continues processing
+// to its children.
 func (c *command) Execute(ctx context.Context, v []string) (context.Context, ExitStatus) {
+	return chain(c.run, c.middleware)(ctx, v)
+}
+
+func (c *command) run(ctx context.Context, v []string) (context.Context, ExitStatus) {
 	if c.cfn != nil {
 		c.hasRun = true
 		return c.cfn(ctx, v)
 	}
+	if len(c.children) > 0 {
+		return ctx, ExitNo
+	}
 	return ctx, ExitFailure
 }
 
@@ -255,25 +388,32 @@ type Group struct {
 	Name     string
 	Priority int
 	Commands []Command
+	Hidden   bool // excluded from instruction and completion
+
+	middleware []Middleware
 }
 
 // Returns a new group provided the string name, priority integer, and any
 // number of Command.
 func NewGroup(name string, priority int, cs ...Command) *Group {
-	return &Group{name, priority, cs}
+	return &Group{name, priority, cs, false, nil}
 }
 
 // Set the groups sorting parameter. "alpha" indicating alphabetic sorting
 // is the only currently available outside of the default sort by priority.
 func (g *Group) SortCommandsBy(s string) {
+	sortCommands(g.Commands, s)
+}
+
+func sortCommands(cs []Command, s string) {
 	var sfn func(int, int) bool
 	switch s {
 	case "alpha":
-		sfn = func(i, j int) bool { return g.Commands[i].Tag() < g.Commands[j].Tag() }
+		sfn = func(i, j int) bool { return cs[i].Tag() < cs[j].Tag() }
 	default:
-		sfn = func(i, j int) bool { return g.Commands[i].Priority() < g.Commands[j].Priority() }
+		sfn = func(i, j int) bool { return cs[i].Priority() < cs[j].Priority() }
 	}
-	sort.SliceStable(g.Commands, sfn)
+	sort.SliceStable(cs, sfn)
 }
 
 // Writes the entire group usage to the provided io.Writer.
@@ -287,15 +427,46 @@ func (g *Group) Use(o io.Writer) {
 // An interface for command execution.
 type Executer interface {
 	Execute(context.Context, []string) int
+	ExecuteE(context.Context, []string) (*Result, error)
+}
+
+// An optional interface of an Executer for resetting every Command FlagSet
+// before execution, implemented by Flippers of New.
+type FlagResetter interface {
+	SetResetFlags(bool)
+}
+
+// The result of executing arguments: the final ExitStatus, its process exit
+// code (see ExitCoder), any handled signal interrupting execution (see
+// Signaler), the Commands executed in order, the context.Context returned by
+// the last Command, and any errors of Cleanup functions joined.
+type Result struct {
+	Status     ExitStatus
+	Code       int
+	Signal     os.Signal
+	Ran        []Command
+	Context    context.Context
+	CleanupErr error
 }
 
 type executer struct {
-	iscmdfn isCommandFunc
-	cleanfn runCleanupFunc
+	name       string
+	cm         Commander
+	iscmdfn    isCommandFunc
+	cleanfn    runCleanupFunc
+	cfgfn      func() error
+	codefn     func(ExitStatus) int
+	signals    []os.Signal
+	middleware []Middleware
+	recovery   int
+	reset      bool
+	keep       bool
+	proceed    bool
+	response   ResponseFiles
 }
 
-func newExecuter(cm Commander, cu runCleanupFunc) *executer {
-	return &executer{isCommand(cm), cu}
+func newExecuter(name string, cm Commander, cu runCleanupFunc, cfg func() error, code func(ExitStatus) int) *executer {
+	return &executer{name, cm, isCommand(cm), cu, cfg, code, nil, nil, recoverNone, false, false, false, ResponseFilesOff}
 }
 
 type queueCmd struct {
@@ -304,10 +475,23 @@ type queueCmd struct {
 	escapes bool
 }
 
-type isCommandFunc func(string) *queueCmd
+// Given the last found command (nil if none) and a string argument, returns
+// a matching command or nil.
+type isCommandFunc func(*queueCmd, string) *queueCmd
 
+// Resolves an argument against the children of the last found command, then
+// the children of each of its parents, then all top level commands.
 func isCommand(cm Commander) isCommandFunc {
-	return func(s string) *queueCmd {
+	return func(at *queueCmd, s string) *queueCmd {
+		if at != nil {
+			for p := at.Command; p != nil; p = p.Parent() {
+				for _, ch := range p.Children() {
+					if s == ch.Tag() {
+						return &queueCmd{at.Group, ch, ch.Escapes()}
+					}
+				}
+			}
+		}
 		gs := cm.Groups()
 		for _, g := range gs.Has {
 			for _, cmd := range g.Commands {
@@ -320,32 +504,59 @@ func isCommand(cm Commander) isCommandFunc {
 	}
 }
 
+func descends(cmd, from Command) bool {
+	for p := cmd.Parent(); p != nil; p = p.Parent() {
+		if p == from {
+			return true
+		}
+	}
+	return false
+}
+
 // An integer type useful for marking results of commands.
 type ExitStatus int
 
 const (
-	ExitNo         ExitStatus = 999  // continue processing commands
-	ExitSuccess    ExitStatus = 0    // return 0
-	ExitFailure    ExitStatus = -1   // return -1
-	ExitUsageError ExitStatus = -2   // return -2
-	ExitAny        ExitStatus = -666 // status for cleaning function setup, never return
+	ExitNo          ExitStatus = 999  // continue processing commands
+	ExitSuccess     ExitStatus = 0    // return 0
+	ExitFailure     ExitStatus = -1   // return -1
+	ExitUsageError  ExitStatus = -2   // return -2
+	ExitAny         ExitStatus = -666 // status for cleaning function setup, never return
+	ExitInterrupted ExitStatus = -128 // execution interrupted by a handled signal
+	ExitPanic       ExitStatus = -129 // a recovered panic of a command
+)
+
+// Failure statuses after sysexits.h, each with its sysexits exit code by
+// default, e.g. ExitConfigError exits 78.
+const (
+	ExitDataError   ExitStatus = -65 // input data incorrect
+	ExitNoInput     ExitStatus = -66 // input file missing or unreadable
+	ExitUnavailable ExitStatus = -69 // a required service unavailable
+	ExitSoftware    ExitStatus = -70 // internal software error
+	ExitIOError     ExitStatus = -74 // input or output error
+	ExitTempFail    ExitStatus = -75 // temporary failure, may be retried
+	ExitNoPerm      ExitStatus = -77 // insufficient permission
+	ExitConfigError ExitStatus = -78 // configuration error
 )
 
 type pop struct {
 	*queueCmd
+	root        *pop
 	start, stop int
 	v           []string
 }
 
 type pops []*pop
 
+// sorts by the top level command of each pop, nested commands remaining
+// in argument order after their top level command
 func (p pops) sort() {
 	sort.SliceStable(p, func(i, j int) bool {
-		return p[i].Group.Priority < p[j].Group.Priority
+		return p[i].root.Group.Priority < p[j].root.Group.Priority
 	})
 	sort.SliceStable(p, func(i, j int) bool {
-		if p[i].Group.Name == p[j].Group.Name {
-			return p[i].Command.Priority() < p[j].Command.Priority()
+		if p[i].root.Group.Name == p[j].root.Group.Name {
+			return p[i].root.Command.Priority() < p[j].root.Command.Priority()
 		}
 		return false
 	})
@@ -353,14 +564,32 @@ func (p pops) sort() {
 
 func queue(fn isCommandFunc, arguments []string) pops {
 	var ps pops
+	var at *pop
+	var esc Command
 
 	for i, v := range arguments {
-		if qc := fn(v); qc != nil {
-			a := &pop{qc, i, 0, nil}
-			ps = append(ps, a)
-			if a.escapes {
-				break
-			}
+		var qc *queueCmd
+		if at != nil {
+			qc = fn(at.queueCmd, v)
+		} else {
+			qc = fn(nil, v)
+		}
+		if qc == nil {
+			continue
+		}
+		// after an escaping command only its own children are found
+		if esc != nil && !descends(qc.Command, esc) {
+			continue
+		}
+		a := &pop{qc, nil, i, 0, nil}
+		a.root = a
+		if qc.Command.Parent() != nil && at != nil {
+			a.root = at.root
+		}
+		ps = append(ps, a)
+		at = a
+		if a.escapes {
+			esc = a.Command
 		}
 	}
 
@@ -385,40 +614,114 @@ func queue(fn isCommandFunc, arguments []string) pops {
 	return ps
 }
 
-func execute(ctx context.Context, cmd Command, arguments []string) (context.Context, ExitStatus) {
-	err := cmd.Parse(arguments)
-	if err != nil {
-		return ctx, ExitUsageError
-	}
-	return cmd.Execute(ctx, arguments)
-}
-
 // The Execute function taking a context.Context, and slice of string arguments,
 // returning an integer corresponding to an ExitStatus.
 func (e *executer) Execute(ctx context.Context, arguments []string) int {
-	var exit ExitStatus
-	switch {
-	case len(arguments) <= 1:
-		goto INSTRUCTION
-	default:
-		q := queue(e.iscmdfn, arguments)
-		for _, p := range q {
-			cmd := p.Command
-			args := p.v[1:]
-			ctx, exit = execute(ctx, cmd, args)
-			switch exit {
-			case ExitSuccess:
-				return e.cleanfn(exit, ctx)
-			case ExitFailure:
-				return e.cleanfn(exit, ctx)
-			case ExitUsageError:
-				goto INSTRUCTION
-			default:
-				continue
+	r, _ := e.ExecuteE(ctx, arguments)
+	return int(r.Status)
+}
+
+// Set resetting the FlagSet of every Command (see FlagSet Reset) before each
+// execution, so that every execution parses flags from their defaults, the
+// values held by the bridge functions of RegexVar flags excepted.
+func (e *executer) SetResetFlags(reset bool) {
+	e.reset = reset
+}
+
+func resetCommands(cm Commander) error {
+	var reset func([]Command) error
+	reset = func(cs []Command) error {
+		for _, cmd := range cs {
+			if r, ok := cmd.(interface{ Reset() error }); ok {
+				if err := r.Reset(); err != nil {
+					return err
+				}
+			}
+			if err := reset(cmd.Children()); err != nil {
+				return err
 			}
 		}
+		return nil
 	}
+	for _, g := range cm.Groups().Has {
+		if err := reset(g.Commands); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-INSTRUCTION:
-	return e.cleanfn(ExitUsageError, ctx)
+// Executes as Execute, returning a Result and any error: a configuration
+// error, a ResponseFileError, a flag parsing error (UnknownFlagError,
+// MissingValueError, InvalidValueError, ConstraintError), a CommandError, a
+// SignalError, a PanicError, or ErrNoCommand. Cleanup functions run as in Execute.
+func (e *executer) ExecuteE(ctx context.Context, arguments []string) (*Result, error) {
+	r := &Result{Status: ExitUsageError}
+	ctx, sigfn, stop := e.notify(ctx)
+	err := e.execute(ctx, arguments, r, sigfn)
+	if s := sigfn(); s != nil {
+		r.Status, r.Signal, err = ExitInterrupted, s, &SignalError{s}
+	}
+	r.CleanupErr = e.cleanfn(r.Status, r.Context)
+	stop()
+	if pe, ok := err.(*PanicError); ok && e.recovery == recoverRepanic {
+		panic(pe.Value)
+	}
+	switch {
+	case r.Signal != nil:
+		r.Code = signalCode(r.Signal)
+	case e.codefn != nil:
+		r.Code = e.codefn(r.Status)
+	}
+	return r, err
+}
+
+func (e *executer) execute(ctx context.Context, arguments []string, r *Result, sigfn func() os.Signal) error {
+	r.Context = ctx
+	if e.cfgfn != nil {
+		if err := e.cfgfn(); err != nil {
+			return err
+		}
+	}
+	if e.reset {
+		if err := resetCommands(e.cm); err != nil {
+			return err
+		}
+	}
+	arguments, status, err := e.expand(arguments)
+	if err != nil {
+		r.Status = status
+		return err
+	}
+	if len(arguments) <= 1 {
+		return ErrNoCommand
+	}
+	q := queue(e.iscmdfn, arguments)
+	for _, p := range q {
+		if sigfn() != nil {
+			return nil
+		}
+		cmd := p.Command
+		args := p.v[1:]
+		if err := cmd.Parse(args); err != nil {
+			r.Status = ExitUsageError
+			return err
+		}
+		r.Ran = append(r.Ran, cmd)
+		var pe *PanicError
+		ctx, r.Status, pe = e.run(cmd, chain(chain(cmd.Execute, p.Group.middleware), e.middleware), ctx, args)
+		r.Context = ctx
+		switch {
+		case pe != nil:
+			return pe
+		case r.Status == ExitSuccess:
+			return nil
+		case r.Status == ExitNo:
+			continue
+		default:
+			return &CommandError{cmd, r.Status, ErrorFrom(ctx)}
+		}
+	}
+	r.Status = ExitUsageError
+	return ErrNoCommand
 }
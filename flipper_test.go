@@ -3,6 +3,9 @@ package flip
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 )
@@ -291,3 +294,105 @@ func TestFlip(t *testing.T) {
 		}
 	}
 }
+
+func subCmdSet(ran *[]string, b *bytes.Buffer) Command {
+	mk := func(tag string, escapes bool, exit ExitStatus) Command {
+		fs := NewFlagSet(tag, ContinueOnError)
+		fs.String("name", "", "a name")
+		fs.SetOut(b)
+		return NewCommand(
+			"", tag, tag+" command",
+			1,
+			escapes,
+			func(c context.Context, s []string) (context.Context, ExitStatus) {
+				*ran = append(*ran, tag)
+				return c, exit
+			},
+			fs,
+		)
+	}
+	remote := NewCommand("", "remote", "remote commands", 1, false, nil, NewFlagSet("remote", ContinueOnError))
+	return remote.SetChild(
+		mk("add", false, ExitSuccess),
+		mk("list", false, ExitNo),
+		mk("show", true, ExitNo).SetChild(mk("detail", false, ExitSuccess)),
+	)
+}
+
+var subExpect = []struct {
+	expectExit int
+	expectRan  []string
+	expectHelp []string
+	cmd        []string
+}{
+	{0, []string{"add"}, nil, []string{"testing", "remote", "add", "-name", "x"}},
+	{0, []string{"list", "add"}, nil, []string{"testing", "remote", "list", "add"}},
+	{-2, nil, []string{"test [OPTIONS...] {COMMAND} ..."}, []string{"testing", "add", "-name", "x"}},
+	{-2, nil, []string{"test [OPTIONS...] {COMMAND} ..."}, []string{"testing", "remote"}},
+	{-2, []string{"show"}, nil, []string{"testing", "remote", "show", "add", "list"}},
+	{0, []string{"show", "detail"}, nil, []string{"testing", "remote", "show", "add", "detail"}},
+	{0, nil, []string{"remote add [<flags>]:", "remote show detail [<flags>]:"}, []string{"testing", "help", "remote"}},
+	{0, nil, []string{"remote add [<flags>]:"}, []string{"testing", "help", "remote add"}},
+}
+
+func TestSubcommand(t *testing.T) {
+	for _, x := range subExpect {
+		var ran []string
+		to := new(bytes.Buffer)
+		f := New("test")
+		f.SetOut(to)
+		f.AddBuiltIn("help").
+			SetGroup("remote", 1, subCmdSet(&ran, to))
+		res := f.Execute(context.Background(), x.cmd)
+		if res != x.expectExit {
+			t.Errorf("cmd %s expected %d, but received %d", x.cmd, x.expectExit, res)
+		}
+		if !reflect.DeepEqual(ran, x.expectRan) {
+			t.Errorf("cmd %s expected to run %v, but ran %v", x.cmd, x.expectRan, ran)
+		}
+		help := to.String()
+		for _, v := range x.expectHelp {
+			if !strings.Contains(help, v) {
+				t.Errorf("Expected help string did not contain %s:\n\n%s", v, help)
+			}
+		}
+	}
+}
+
+func TestResetFlags(t *testing.T) {
+	b := new(bytes.Buffer)
+	var name string
+	var seen []string
+	fs := NewFlagSet("run", ContinueOnError)
+	fs.StringVar(&name, "name", "default", "a name")
+	fs.RegexVar("match", "a match", func(s string, rs ...*regexp.Regexp) error {
+		if !rs[0].MatchString(s) {
+			return fmt.Errorf("no match")
+		}
+		return nil
+	}, "^[a-z]+$")
+	fs.SetOut(b)
+	run := NewCommand("", "run", "run command", 1, false,
+		func(c context.Context, s []string) (context.Context, ExitStatus) {
+			seen = append(seen, name)
+			return c, ExitSuccess
+		},
+		fs,
+	)
+	f := New("test")
+	f.SetOut(b)
+	f.SetGroup("run", 1, NewCommand("", "parent", "parent command", 1, false, nil, NewFlagSet("parent", ContinueOnError)).SetChild(run))
+
+	lines := [][]string{{"test", "parent", "run", "-name", "x"}, {"test", "parent", "run"}}
+	for _, l := range lines {
+		f.Execute(context.Background(), l)
+	}
+	var fr FlagResetter = f
+	fr.SetResetFlags(true)
+	for _, l := range lines {
+		f.Execute(context.Background(), l)
+	}
+	if expect := []string{"x", "x", "x", "default"}; !reflect.DeepEqual(seen, expect) {
+		t.Errorf("expected %v, got %v", expect, seen)
+	}
+}
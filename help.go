@@ -0,0 +1,226 @@
+package flip
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// The data provided to an instruction template: the program name and every
+// visible Group with Commands, by priority.
+type HelpData struct {
+	Name   string
+	Groups []*GroupHelp
+}
+
+// The data of a Group provided to an instruction template, with the Group
+// Commands by priority.
+type GroupHelp struct {
+	Name     string
+	Priority int
+	Commands []*CommandHelp
+}
+
+// The data provided to a command template. Constraints are the non required
+// flag constraints as usage strings, and Children the nested Commands by
+// priority.
+type CommandHelp struct {
+	Group       string
+	Tag         string
+	Path        string
+	Use         string
+	Priority    int
+	Escapes     bool
+	Flags       []*FlagHelp
+	Constraints []string
+	Children    []*CommandHelp
+	Command     Command
+}
+
+// The data of a flag provided to a command template. Names are the names as
+// in usage (e.g. "-v, --verbose"), Type the usage type name (empty for a
+// boolean flag), Default the default value when not a zero value, DefValue
+// the default value as a string in any case, Separator
+// the separator of a repeatable flag if any, and Line the complete usage line
+// of the flag as written by FlagSet Usage.
+type FlagHelp struct {
+	Name       string
+	Short      string
+	Names      string
+	Type       string
+	Usage      string
+	Default    string
+	DefValue   string
+	Env        []string
+	Separator  string
+	Required   bool
+	Repeatable bool
+	Line       string
+}
+
+// Returns the HelpData of the provided program name and Commander.
+func NewHelpData(name string, cm Commander) *HelpData {
+	h := &HelpData{Name: name}
+	gs := cm.Groups()
+	gs.SortGroupsBy("")
+	for _, g := range gs.Has {
+		if g.Hidden || len(g.Commands) == 0 {
+			continue
+		}
+		g.SortCommandsBy("default")
+		gh := &GroupHelp{Name: g.Name, Priority: g.Priority}
+		for _, cmd := range g.Commands {
+			gh.Commands = append(gh.Commands, NewCommandHelp(cmd))
+		}
+		h.Groups = append(h.Groups, gh)
+	}
+	return h
+}
+
+// Returns the CommandHelp of the provided Command, and of its children.
+func NewCommandHelp(cmd Command) *CommandHelp {
+	ch := &CommandHelp{
+		Group:    cmd.Group(),
+		Tag:      cmd.Tag(),
+		Path:     cmd.Path(),
+		Use:      cmd.UseString(),
+		Priority: cmd.Priority(),
+		Escapes:  cmd.Escapes(),
+		Flags:    flagHelp(cmd),
+		Command:  cmd,
+	}
+	if c, ok := cmd.(interface{ Constraints() []*Constraint }); ok {
+		for _, cn := range c.Constraints() {
+			if cn.Kind != Required {
+				ch.Constraints = append(ch.Constraints, cn.String())
+			}
+		}
+	}
+	cs := cmd.Children()
+	sortCommands(cs, "default")
+	for _, c := range cs {
+		ch.Children = append(ch.Children, NewCommandHelp(c))
+	}
+	return ch
+}
+
+// returns the FlagHelp of every flag of the provided Flagger
+func flagHelp(fs Flagger) []*FlagHelp {
+	var ret []*FlagHelp
+	fs.VisitAll(func(flag *Flag) {
+		kind, usage := UnquoteMessage(flag)
+		fh := &FlagHelp{
+			Name:     flag.Name,
+			Short:    flag.Short,
+			Names:    flag.Names(),
+			Type:     kind,
+			Usage:    usage,
+			DefValue: flag.DefValue,
+		}
+		if !isZeroValue(flag.DefValue) {
+			fh.Default = flag.DefValue
+		}
+		if e, ok := fs.(interface{ EnvNames(string) []string }); ok {
+			fh.Env = e.EnvNames(flag.Name)
+		}
+		if r, ok := fs.(interface{ IsRequired(string) bool }); ok {
+			fh.Required = r.IsRequired(flag.Name)
+		}
+		if r, ok := flag.Value.(repeatableFlag); ok {
+			fh.Repeatable = r.IsRepeatable()
+		}
+		if sv, ok := flag.Value.(*sliceValue); ok {
+			fh.Separator = sv.sep
+		}
+		fh.Line = flagLine(flag, fh.Env, fh.Required)
+		ret = append(ret, fh)
+	})
+	return ret
+}
+
+// The default instruction template, executed with HelpData.
+const DefaultInstructionTemplate = `{{title (printf "%s [OPTIONS...] {COMMAND} ...\n\n" .Name)}}` +
+	`{{range .Groups}}{{range .Commands}}{{use .}}{{end}}{{end}}`
+
+// The default command template, executed with CommandHelp.
+const DefaultCommandTemplate = `{{white (printf "-----\n%s [<flags>]:\n" .Path)}}` +
+	`{{white (printf "\t%s\n\n" .Use)}}` +
+	`{{range .Flags}}{{white .Line "\n"}}{{end}}` +
+	`{{range .Constraints}}{{white (printf "\t%s\n" .)}}{{end}}` + "\n" +
+	`{{range .Children}}{{use .}}{{end}}`
+
+func colorString(c func(io.Writer, ...interface{})) func(...interface{}) string {
+	return func(a ...interface{}) string {
+		b := new(bytes.Buffer)
+		c(b, a...)
+		return b.String()
+	}
+}
+
+// A set of command templates by Command path, "" for the default.
+type helper struct {
+	commands map[string]*template.Template
+}
+
+func newHelper() *helper {
+	h := &helper{make(map[string]*template.Template)}
+	h.commands[""] = template.Must(h.parse("command", DefaultCommandTemplate))
+	return h
+}
+
+var defaultHelper = newHelper()
+
+// Template functions available to instruction and command templates:
+//
+//	use      executes the command template of a CommandHelp
+//	title    colors its arguments as the instruction title
+//	white    colors its arguments as usage
+//	join     strings.Join
+//	upper    strings.ToUpper
+//	lower    strings.ToLower
+func (h *helper) funcs() template.FuncMap {
+	return template.FuncMap{
+		"use":   h.use,
+		"title": colorString(Color(Bold, FgHiWhite)),
+		"white": colorString(white),
+		"join":  strings.Join,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+func (h *helper) parse(name, t string) (*template.Template, error) {
+	return template.New(name).Funcs(h.funcs()).Parse(t)
+}
+
+func (h *helper) use(ch *CommandHelp) (string, error) {
+	t, ok := h.commands[ch.Path]
+	if !ok {
+		t = h.commands[""]
+	}
+	b := new(bytes.Buffer)
+	err := t.Execute(b, ch)
+	return b.String(), err
+}
+
+func (h *helper) write(o io.Writer, ch *CommandHelp) {
+	s, err := h.use(ch)
+	fmt.Fprint(o, s)
+	if err != nil {
+		fmt.Fprintln(o, err)
+	}
+}
+
+// Sets the command template of the Command of the provided path (e.g. "remote
+// add"), or of every Command without one when the path is empty, returning any
+// template parsing error.
+func (h *helper) SetCommandTemplate(path, t string) error {
+	tmpl, err := h.parse("command", t)
+	if err != nil {
+		return err
+	}
+	h.commands[path] = tmpl
+	return nil
+}
@@ -0,0 +1,89 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestHelpTemplate(t *testing.T) {
+	b := new(bytes.Buffer)
+	f := docsFlipper(b)
+
+	h := NewHelpData("test", f.Commander)
+	var names []string
+	for _, g := range h.Groups {
+		names = append(names, g.Name)
+	}
+	if strings.Join(names, " ") != "completion docs remote" {
+		t.Errorf("unexpected help groups %v", names)
+	}
+	push := NewCommandHelp(f.GetCommand("remote push")[0])
+	if len(push.Flags) != 3 {
+		t.Fatalf("expected 3 flags, got %d", len(push.Flags))
+	}
+	name := push.Flags[1]
+	if name.Names != "-n, --name" || name.Type != "string" || name.Default != "origin" ||
+		strings.Join(name.Env, ",") != "PUSH_NAME" || !name.Required || name.Repeatable {
+		t.Errorf("unexpected flag help %+v", name)
+	}
+	if tag := push.Flags[2]; !tag.Repeatable || tag.Default != "" {
+		t.Errorf("unexpected flag help %+v", tag)
+	}
+
+	if err := f.SetTemplate("{{.Name}}:{{range .Groups}} {{.Name}}{{range .Commands}}{{use .}}{{end}}{{end}}\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetCommandTemplate("", "[{{.Path}}{{range .Children}}{{use .}}{{end}}]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetCommandTemplate("remote push", `<{{range .Flags}}{{.Name}}{{if .Required}}!{{end}}{{end}}>`); err != nil {
+		t.Fatal(err)
+	}
+	b.Reset()
+	f.Instruction(context.Background())
+	expect := "test: completion[completion] docs[docs] remote[remote[remote add][remote list][remote show[remote show detail]]<forcename!tag>]\n"
+	if b.String() != expect {
+		t.Errorf("expected instruction\n%q\ngot\n%q", expect, b.String())
+	}
+
+	b.Reset()
+	f.SubsetInstruction(f.GetCommand("remote push")...)(context.Background())
+	if b.String() != "<forcename!tag>" {
+		t.Errorf("unexpected subset instruction %q", b.String())
+	}
+
+	if err := f.SetCommandTemplate("", "{{.Path"); err == nil {
+		t.Error("expected template parsing error")
+	}
+	b.Reset()
+	f.GetCommand("remote push")[0].Use(b)
+	if b.String() != "<forcename!tag>" {
+		t.Errorf("command Use not by command template: %q", b.String())
+	}
+	b.Reset()
+	NewCommand("", "loose", "loose command", 1, false, nil, NewFlagSet("loose", ContinueOnError)).Use(b)
+	if !strings.Contains(b.String(), "loose [<flags>]:") {
+		t.Errorf("command Use not by default template: %q", b.String())
+	}
+
+	f.SwapInstructer(plainInstructer{b})
+	if err := f.SetTemplate("{{.Name}}"); err != ErrNoTemplates {
+		t.Errorf("expected ErrNoTemplates, got %v", err)
+	}
+}
+
+// an Instructer without templates
+type plainInstructer struct {
+	o io.Writer
+}
+
+func (i plainInstructer) SwapInstructer(Instructer)   {}
+func (i plainInstructer) Instruction(context.Context) {}
+func (i plainInstructer) Out() io.Writer              { return i.o }
+func (i plainInstructer) SetOut(o io.Writer)          {}
+func (i plainInstructer) SubsetInstruction(...Command) func(context.Context) {
+	return func(context.Context) {}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"text/template"
 )
 
 // An interface for providing instruction i.e. writes usage strings.
@@ -15,6 +16,15 @@ type Instructer interface {
 	Writer
 }
 
+// An optional interface of an Instructer for setting the text/template
+// layouts of instruction: an instruction template executed with HelpData, and
+// command templates executed with CommandHelp, either for every Command or per
+// Command path. See DefaultInstructionTemplate and DefaultCommandTemplate.
+type Templater interface {
+	SetTemplate(string) error
+	SetCommandTemplate(string, string) error
+}
+
 type iswapper struct {
 	Instructer
 }
@@ -23,14 +33,44 @@ func (s *iswapper) SwapInstructer(i Instructer) {
 	s.Instructer = i
 }
 
+// Sets the instruction template of the Instructer, returning ErrNoTemplates
+// if it is not a Templater.
+func (s *iswapper) SetTemplate(t string) error {
+	if tr, ok := s.Instructer.(Templater); ok {
+		return tr.SetTemplate(t)
+	}
+	return ErrNoTemplates
+}
+
+// Sets the command template of the Command of the provided path (e.g. "remote
+// add"), or of every Command without one when the path is empty, for both
+// instruction and Command Use, returning ErrNoTemplates if the Instructer is
+// not a Templater.
+func (s *iswapper) SetCommandTemplate(path, t string) error {
+	if tr, ok := s.Instructer.(Templater); ok {
+		return tr.SetCommandTemplate(path, t)
+	}
+	return ErrNoTemplates
+}
+
+// returns the command templates of the Instructer, or nil
+func (s *iswapper) helper() *helper {
+	if i, ok := s.Instructer.(*instructer); ok {
+		return i.helper
+	}
+	return nil
+}
+
 type instructer struct {
-	titleFmtString string
-	output         io.Writer
-	ifn            Cleanup
+	tmpl   *template.Template
+	output io.Writer
+	ifn    Cleanup
+	*helper
 }
 
 func newInstructer(tag string, cm Commander, o io.Writer) *iswapper {
-	i := &instructer{"%s [OPTIONS...] {COMMAND} ...\n\n", o, nil}
+	i := &instructer{nil, o, nil, newHelper()}
+	i.tmpl = template.Must(i.parse("instruction", DefaultInstructionTemplate))
 	i.ifn = defaultInstruction(tag, cm, i)
 	return &iswapper{i}
 }
@@ -48,29 +88,30 @@ func (i *instructer) SubsetInstruction(cs ...Command) func(context.Context) {
 		out := i.Out()
 		b := new(bytes.Buffer)
 		for _, cmd := range cs {
-			cmd.Use(b)
+			i.write(b, NewCommandHelp(cmd))
 		}
 		fmt.Fprint(out, b)
 	}
 }
 
-func titleString(titleFmtString, name string, b io.Writer) {
-	title := Color(Bold, FgHiWhite)
-	title(b, fmt.Sprintf(titleFmtString, name))
+// Sets the instruction template, executed with HelpData, returning any
+// template parsing error.
+func (i *instructer) SetTemplate(t string) error {
+	tmpl, err := i.parse("instruction", t)
+	if err != nil {
+		return err
+	}
+	i.tmpl = tmpl
+	return nil
 }
 
 func defaultInstruction(tag string, cm Commander, i *instructer) Cleanup {
 	return func(c context.Context) {
 		out := i.Out()
 		b := new(bytes.Buffer)
-		titleString(i.titleFmtString, tag, b)
-
-		gs := cm.Groups()
-		gs.SortGroupsBy("")
-		for _, g := range gs.Has {
-			g.Use(b)
+		if err := i.tmpl.Execute(b, NewHelpData(tag, cm)); err != nil {
+			fmt.Fprintln(b, err)
 		}
-
 		fmt.Fprint(out, b)
 	}
 }
@@ -0,0 +1,34 @@
+package flip
+
+// A function wrapping a CommandFunc, e.g. for logging, authorization, or
+// timing, calling the wrapped CommandFunc or not.
+type Middleware func(CommandFunc) CommandFunc
+
+// An optional interface of a Command or an Executer for setting Middleware
+// wrapping a CommandFunc, implemented by Commands of NewCommand and Flippers
+// of New.
+type Middlewarer interface {
+	SetMiddleware(...Middleware)
+}
+
+// returns the provided CommandFunc wrapped by the provided Middleware, the
+// first outermost
+func chain(fn CommandFunc, mws []Middleware) CommandFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		fn = mws[i](fn)
+	}
+	return fn
+}
+
+// Set the provided Middleware to wrap execution of every Command, the first
+// provided outermost. Middleware set for execution wraps any Group
+// Middleware, which wraps any Command Middleware.
+func (e *executer) SetMiddleware(mws ...Middleware) {
+	e.middleware = append(e.middleware, mws...)
+}
+
+// Set the provided Middleware to wrap execution of every Command of the
+// Group, including nested Commands, the first provided outermost.
+func (g *Group) SetMiddleware(mws ...Middleware) {
+	g.middleware = append(g.middleware, mws...)
+}
@@ -0,0 +1,61 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func record(to *[]string, name string) Middleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(c context.Context, a []string) (context.Context, ExitStatus) {
+			*to = append(*to, name+">")
+			c, s := next(c, a)
+			*to = append(*to, "<"+name)
+			return c, s
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	b := new(bytes.Buffer)
+	var ran []string
+	remote := subCmdSet(&ran, b)
+	f := New("test")
+	f.SetOut(b)
+	f.SetGroup("remote", 1, remote)
+
+	var calls []string
+	f.SetMiddleware(record(&calls, "g1"), record(&calls, "g2"))
+	f.GetGroup("remote").SetMiddleware(record(&calls, "grp"))
+	add := remote.Children()[0].(Middlewarer)
+	add.SetMiddleware(record(&calls, "add"))
+	add.SetMiddleware(func(next CommandFunc) CommandFunc {
+		return func(c context.Context, a []string) (context.Context, ExitStatus) {
+			if len(a) > 0 && a[0] == "deny" {
+				return c, ExitFailure
+			}
+			return next(c, a)
+		}
+	})
+
+	if res := f.Execute(context.Background(), []string{"test", "remote", "add"}); res != 0 {
+		t.Fatalf("expected 0, got %d", res)
+	}
+	expect := "g1> g2> grp> <grp <g2 <g1 g1> g2> grp> add> <add <grp <g2 <g1"
+	if got := strings.Join(calls, " "); got != expect {
+		t.Errorf("expected middleware calls\n%s\ngot\n%s", expect, got)
+	}
+	if strings.Join(ran, " ") != "add" {
+		t.Errorf("unexpected commands run %v", ran)
+	}
+
+	calls, ran = nil, nil
+	if res := f.Execute(context.Background(), []string{"test", "remote", "add", "deny"}); res != -1 {
+		t.Errorf("expected -1, got %d", res)
+	}
+	if len(ran) != 0 {
+		t.Errorf("denied command ran: %v", ran)
+	}
+}
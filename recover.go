@@ -0,0 +1,79 @@
+package flip
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// An optional interface of a Flipper for opting in to recovery of panics
+// during execution, implemented by Flippers of New.
+type Recoverer interface {
+	RecoverPanics(bool)
+}
+
+// An error type returned from ExecuteE when a Command panics, containing the
+// Command, the panic value, and the stack of the panic.
+type PanicError struct {
+	Command Command
+	Value   interface{}
+	Stack   []byte
+}
+
+// Returns the PanicError as a string.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("command %s panicked: %v", e.Command.Path(), e.Value)
+}
+
+// Returns the panic value, if an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+type panicKey struct{}
+
+// Returns the PanicError carried by the provided context.Context, as provided
+// to Cleanup functions after a recovered panic, or nil.
+func PanicFrom(ctx context.Context) *PanicError {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(panicKey{}).(*PanicError)
+	return p
+}
+
+const (
+	recoverNone = iota
+	recoverFail
+	recoverRepanic
+)
+
+// Recovers a panic of any Command, or of Middleware, during execution,
+// ending execution with ExitPanic and a PanicError, available to Cleanup
+// functions by PanicFrom. After the Cleanup functions of ExitPanic and
+// ExitAny run, the panic value is panicked again if repanic is true, or
+// returned as a failure otherwise.
+func (e *executer) RecoverPanics(repanic bool) {
+	e.recovery = recoverFail
+	if repanic {
+		e.recovery = recoverRepanic
+	}
+}
+
+// runs the provided CommandFunc of the provided Command, recovering any panic
+// as configured
+func (e *executer) run(cmd Command, fn CommandFunc, ctx context.Context, args []string) (rctx context.Context, s ExitStatus, p *PanicError) {
+	if e.recovery == recoverNone {
+		rctx, s = fn(ctx, args)
+		return
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			p = &PanicError{cmd, v, debug.Stack()}
+			rctx, s = context.WithValue(ctx, panicKey{}, p), ExitPanic
+		}
+	}()
+	rctx, s = fn(ctx, args)
+	return
+}
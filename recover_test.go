@@ -0,0 +1,91 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func panicFlipper(b *bytes.Buffer, cleaned *[]string) *flipper {
+	f := New("test")
+	f.SetOut(b)
+	f.SetGroup("panic", 1,
+		NewCommand("", "panic", "panics", 1, false,
+			func(c context.Context, a []string) (context.Context, ExitStatus) {
+				panic(errBroken)
+			},
+			NewFlagSet("panic", ContinueOnError),
+		),
+		NewCommand("", "status", "returns ExitPanic", 2, false,
+			func(c context.Context, a []string) (context.Context, ExitStatus) {
+				return c, ExitPanic
+			},
+			NewFlagSet("status", ContinueOnError),
+		),
+	)
+	f.SetCleanup(ExitPanic, func(c context.Context) {
+		if p := PanicFrom(c); p != nil {
+			*cleaned = append(*cleaned, "panic:"+p.Command.Tag())
+		}
+	})
+	f.SetCleanup(ExitAny, func(context.Context) { *cleaned = append(*cleaned, "any") })
+	return f
+}
+
+func TestRecoverPanics(t *testing.T) {
+	b := new(bytes.Buffer)
+	var cleaned []string
+	f := panicFlipper(b, &cleaned)
+	f.RecoverPanics(false)
+
+	r, err := f.ExecuteE(context.Background(), []string{"test", "panic"})
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Value != errBroken || !errors.Is(err, errBroken) ||
+		!strings.Contains(string(pe.Stack), "recover_test.go") {
+		t.Errorf("expected panic error, got %v", err)
+	}
+	if r.Status != ExitPanic || r.Code != 70 || PanicFrom(r.Context) != pe {
+		t.Errorf("unexpected panic result %+v", r)
+	}
+	if strings.Join(cleaned, " ") != "panic:panic any" {
+		t.Errorf("unexpected cleanups %v", cleaned)
+	}
+
+	cleaned = nil
+	f.RecoverPanics(true)
+	func() {
+		defer func() {
+			if v := recover(); v != errBroken {
+				t.Errorf("expected repanic of %v, got %v", errBroken, v)
+			}
+		}()
+		f.Execute(context.Background(), []string{"test", "panic"})
+	}()
+	if strings.Join(cleaned, " ") != "panic:panic any" {
+		t.Errorf("unexpected cleanups before repanic %v", cleaned)
+	}
+
+	cleaned = nil
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected unrecovered panic")
+			}
+		}()
+		panicFlipper(b, &cleaned).Execute(context.Background(), []string{"test", "panic"})
+	}()
+	if len(cleaned) != 0 {
+		t.Errorf("unexpected cleanups without recovery %v", cleaned)
+	}
+
+	for _, repanic := range []bool{false, true} {
+		f.RecoverPanics(repanic)
+		r, err = f.ExecuteE(context.Background(), []string{"test", "status"})
+		var ce *CommandError
+		if !errors.As(err, &ce) || ce.Status != ExitPanic || err.Error() == "" || r.Status != ExitPanic {
+			t.Errorf("expected command error for a returned ExitPanic, got %+v, %v", r, err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package flip
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// An integer type for the expansion of response file arguments, see
+// SetResponseFiles.
+type ResponseFiles int
+
+const (
+	ResponseFilesOff    ResponseFiles = iota // no expansion, the default
+	ResponseFilesLines                       // one argument per line
+	ResponseFilesTokens                      // arguments split as by Tokenize
+)
+
+// An optional interface of an Executer for expanding response file
+// arguments, implemented by Flippers of New.
+type ResponseFiler interface {
+	SetResponseFiles(ResponseFiles)
+}
+
+// The error wrapped by a ResponseFileError for a response file including
+// itself.
+var ErrResponseCycle = errors.New("response file includes itself")
+
+// An error type returned from execution for a response file failing to be
+// read or expanded.
+type ResponseFileError struct {
+	Path string
+	Err  error
+}
+
+// Returns the ResponseFileError as a string.
+func (e *ResponseFileError) Error() string {
+	return fmt.Sprintf("response file %s: %v", e.Path, e.Err)
+}
+
+// Returns the error reading or expanding the response file.
+func (e *ResponseFileError) Unwrap() error {
+	return e.Err
+}
+
+// Set expansion of @path arguments, replaced before executing by the
+// arguments read from the file at path, either one per line (empty lines
+// skipped) or split as by Tokenize. Arguments read are expanded in turn, a
+// relative path resolved against the directory of the file including it, and
+// a file including itself returns a ResponseFileError wrapping
+// ErrResponseCycle. An argument beginning @@ is the literal argument with one
+// @ removed, and a lone @ is literal. A file failing to be read ends execution
+// with ExitNoInput, and failing to be expanded with ExitDataError.
+func (e *executer) SetResponseFiles(r ResponseFiles) {
+	e.response = r
+}
+
+func (e *executer) expand(arguments []string) ([]string, ExitStatus, error) {
+	if e.response == ResponseFilesOff || len(arguments) == 0 {
+		return arguments, ExitSuccess, nil
+	}
+	ret := []string{arguments[0]}
+	for _, a := range arguments[1:] {
+		args, err := e.expandArg(a, "", nil)
+		if err != nil {
+			var pe *os.PathError
+			if errors.As(err, &pe) {
+				return nil, ExitNoInput, err
+			}
+			return nil, ExitDataError, err
+		}
+		ret = append(ret, args...)
+	}
+	return ret, ExitSuccess, nil
+}
+
+// expands an argument found in the file at from, with the files including
+// it, if any
+func (e *executer) expandArg(a, from string, including []string) ([]string, error) {
+	switch {
+	case strings.HasPrefix(a, "@@"):
+		return []string{a[1:]}, nil
+	case len(a) < 2 || a[0] != '@':
+		return []string{a}, nil
+	}
+	path := a[1:]
+	if from != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(from), path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	for _, inc := range including {
+		if inc == path {
+			return nil, &ResponseFileError{a[1:], ErrResponseCycle}
+		}
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, &ResponseFileError{a[1:], err}
+	}
+	var args []string
+	switch e.response {
+	case ResponseFilesLines:
+		for _, l := range strings.Split(string(b), "\n") {
+			if l = strings.TrimRight(l, "\r"); l != "" {
+				args = append(args, l)
+			}
+		}
+	case ResponseFilesTokens:
+		if args, err = Tokenize(string(b)); err != nil {
+			return nil, &ResponseFileError{a[1:], err}
+		}
+	}
+	including = append(including, path)
+	var ret []string
+	for _, arg := range args {
+		exp, err := e.expandArg(arg, path, including)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, exp...)
+	}
+	return ret, nil
+}
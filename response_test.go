@@ -0,0 +1,68 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestResponseFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"lines.rsp":     "run\r\n-count\n\n@sub/value.rsp\n",
+		"sub/value.rsp": "7\n",
+		"tokens.rsp":    "run -count '8' # comment\n",
+		"cycle.rsp":     "@again.rsp",
+		"again.rsp":     "@cycle.rsp",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	at := func(name string) string { return "@" + filepath.Join(dir, name) }
+	count := func(r *Result) string {
+		return r.Ran[0].(*command).Lookup("count").Value.String()
+	}
+
+	b := new(bytes.Buffer)
+	f := errorFlipper(b)
+	if _, err := f.ExecuteE(context.Background(), []string{"test", at("lines.rsp")}); err != ErrNoCommand {
+		t.Errorf("expected no expansion by default, got %v", err)
+	}
+
+	var rf ResponseFiler = f
+	rf.SetResponseFiles(ResponseFilesLines)
+	r, err := f.ExecuteE(context.Background(), []string{"test", at("lines.rsp")})
+	if err != nil || count(r) != "7" {
+		t.Errorf("unexpected lines result %+v, %v", r, err)
+	}
+
+	var rfe *ResponseFileError
+	r, err = f.ExecuteE(context.Background(), []string{"test", "run", at("cycle.rsp")})
+	if !errors.As(err, &rfe) || !errors.Is(err, ErrResponseCycle) || r.Status != ExitDataError {
+		t.Errorf("expected cycle error, got %+v, %v", r, err)
+	}
+	r, err = f.ExecuteE(context.Background(), []string{"test", "run", at("missing.rsp")})
+	if !errors.Is(err, os.ErrNotExist) || r.Status != ExitNoInput || r.Code != 66 {
+		t.Errorf("expected missing file error, got %+v, %v", r, err)
+	}
+
+	f.SetResponseFiles(ResponseFilesTokens)
+	r, err = f.ExecuteE(context.Background(), []string{"test", at("tokens.rsp")})
+	if err != nil || count(r) != "8" {
+		t.Errorf("unexpected tokens result %+v, %v", r, err)
+	}
+
+	args, _, err := f.expand([]string{"@@test", "@@x", "@", "a@b"})
+	if want := []string{"@@test", "@x", "@", "a@b"}; err != nil || !reflect.DeepEqual(args, want) {
+		t.Errorf("expected %q, got %q, %v", want, args, err)
+	}
+}
@@ -0,0 +1,119 @@
+package flip
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// The version of the Schema document, incremented on incompatible changes.
+const SchemaVersion = 1
+
+// A machine readable description of a program: every visible Group with
+// Commands, by priority.
+type Schema struct {
+	Version int            `json:"version"`
+	Name    string         `json:"name"`
+	Groups  []*SchemaGroup `json:"groups"`
+}
+
+// A Group of a Schema, with the Group Commands by priority.
+type SchemaGroup struct {
+	Name     string           `json:"name"`
+	Priority int              `json:"priority"`
+	Commands []*SchemaCommand `json:"commands"`
+}
+
+// A Command of a Schema, with any nested Commands by priority.
+type SchemaCommand struct {
+	Tag      string           `json:"tag"`
+	Path     string           `json:"path"`
+	Use      string           `json:"use"`
+	Priority int              `json:"priority"`
+	Escapes  bool             `json:"escapes"`
+	Flags    []*SchemaFlag    `json:"flags"`
+	Commands []*SchemaCommand `json:"commands"`
+}
+
+// A flag of a Schema Command. Type is the type name of UnquoteMessage, empty
+// for a boolean flag.
+type SchemaFlag struct {
+	Name       string   `json:"name"`
+	Short      string   `json:"short,omitempty"`
+	Type       string   `json:"type"`
+	Default    string   `json:"default"`
+	Usage      string   `json:"usage"`
+	Env        []string `json:"env,omitempty"`
+	Required   bool     `json:"required,omitempty"`
+	Repeatable bool     `json:"repeatable,omitempty"`
+	Separator  string   `json:"separator,omitempty"`
+}
+
+// Returns the Schema of the provided program name and Commander.
+func NewSchema(name string, cm Commander) *Schema {
+	h := NewHelpData(name, cm)
+	s := &Schema{SchemaVersion, name, make([]*SchemaGroup, 0, len(h.Groups))}
+	for _, g := range h.Groups {
+		s.Groups = append(s.Groups, &SchemaGroup{g.Name, g.Priority, schemaCommands(g.Commands)})
+	}
+	return s
+}
+
+func schemaCommands(chs []*CommandHelp) []*SchemaCommand {
+	ret := make([]*SchemaCommand, 0, len(chs))
+	for _, ch := range chs {
+		sc := &SchemaCommand{
+			ch.Tag, ch.Path, ch.Use, ch.Priority, ch.Escapes,
+			make([]*SchemaFlag, 0, len(ch.Flags)),
+			schemaCommands(ch.Children),
+		}
+		for _, fh := range ch.Flags {
+			sc.Flags = append(sc.Flags, &SchemaFlag{
+				fh.Name, fh.Short, fh.Type, fh.DefValue, fh.Usage,
+				fh.Env, fh.Required, fh.Repeatable, fh.Separator,
+			})
+		}
+		ret = append(ret, sc)
+	}
+	return ret
+}
+
+// Writes the indented JSON Schema of the provided program name and Commander
+// to the provided io.Writer.
+func WriteSchema(o io.Writer, name string, cm Commander) error {
+	b, err := json.MarshalIndent(NewSchema(name, cm), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(o, "%s\n", b)
+	return err
+}
+
+type schema struct {
+	f *flipper
+}
+
+func (s *schema) command() Command {
+	return NewCommand(
+		"",
+		"schema",
+		`Prints a JSON description of every command and flag.`,
+		1,
+		true,
+		func(c context.Context, a []string) (context.Context, ExitStatus) {
+			if err := WriteSchema(s.f.Out(), s.f.name, s.f.Commander); err != nil {
+				fmt.Fprintln(s.f.Out(), err)
+				return c, ExitFailure
+			}
+			return c, ExitSuccess
+		},
+		NewFlagSet("schema", ContinueOnError),
+	)
+}
+
+func (f *flipper) addSchema() *flipper {
+	s := &schema{f}
+	f.SetGroup("schema", -1000, s.command())
+	return f
+}
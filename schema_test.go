@@ -0,0 +1,53 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	b := new(bytes.Buffer)
+	f := docsFlipper(b)
+	f.AddBuiltIn("schema")
+
+	b.Reset()
+	if res := f.Execute(context.Background(), []string{"test", "schema"}); res != 0 {
+		t.Fatalf("schema failed %d: %s", res, b.String())
+	}
+	s := new(Schema)
+	if err := json.Unmarshal(b.Bytes(), s); err != nil {
+		t.Fatalf("invalid schema json: %v\n%s", err, b.String())
+	}
+	if s.Version != SchemaVersion || s.Name != "test" || len(s.Groups) != 4 {
+		t.Fatalf("unexpected schema %+v", s)
+	}
+	remote := s.Groups[3]
+	if remote.Name != "remote" || remote.Priority != 1 || len(remote.Commands) != 1 {
+		t.Fatalf("unexpected schema group %+v", remote)
+	}
+	cmds := remote.Commands[0].Commands
+	if len(cmds) != 4 || cmds[3].Path != "remote push" || cmds[3].Priority != 2 || cmds[2].Tag != "show" || !cmds[2].Escapes {
+		t.Fatalf("unexpected schema commands %+v", cmds)
+	}
+	if len(cmds[2].Commands) != 1 || cmds[2].Commands[0].Path != "remote show detail" {
+		t.Errorf("unexpected nested schema commands %+v", cmds[2].Commands)
+	}
+	expect := []SchemaFlag{
+		{Name: "force", Type: "", Default: "false", Usage: "force the push"},
+		{Name: "name", Short: "n", Type: "string", Default: "origin", Usage: "the remote name", Env: []string{"PUSH_NAME"}, Required: true},
+		{Name: "tag", Type: "string", Default: "[]", Usage: "tags to push", Repeatable: true},
+	}
+	fs := cmds[3].Flags
+	if len(fs) != len(expect) {
+		t.Fatalf("unexpected schema flags %+v", fs)
+	}
+	for i, e := range expect {
+		got, _ := json.Marshal(fs[i])
+		want, _ := json.Marshal(e)
+		if !bytes.Equal(got, want) {
+			t.Errorf("expected schema flag %s, got %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package flip
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+)
+
+// An optional interface of an Executer for executing command lines from a
+// string or a script, implemented by Flippers of New.
+type LineExecuter interface {
+	ExecuteLine(context.Context, string) (*Result, error)
+	ExecuteScript(context.Context, io.Reader) ([]*LineResult, error)
+	SetScriptContinue(bool)
+	SetKeepFlags(bool)
+}
+
+// The result of executing a command line of a script: the number of its first
+// line in the script, its text, and the Result and error of its execution.
+type LineResult struct {
+	Line   int
+	Text   string
+	Result *Result
+	Err    error
+}
+
+// Executes the provided command line, split into arguments as by Tokenize,
+// as ExecuteE. A line failing to split returns ExitUsageError and
+// ErrUnterminated without executing any Command or Cleanup functions.
+func (e *executer) ExecuteLine(ctx context.Context, line string) (*Result, error) {
+	args, err := Tokenize(line)
+	if err != nil {
+		r := &Result{Status: ExitUsageError, Context: ctx}
+		if e.codefn != nil {
+			r.Code = e.codefn(r.Status)
+		}
+		return r, err
+	}
+	return e.ExecuteE(ctx, append([]string{e.name}, args...))
+}
+
+// Set ExecuteScript to continue executing lines after a line fails, instead
+// of stopping.
+func (e *executer) SetScriptContinue(proceed bool) {
+	e.proceed = proceed
+}
+
+// Executes each command line read from the provided io.Reader as
+// ExecuteLine, with every FlagSet reset to its defaults so each line executes
+// as a separate invocation, unless set by SetKeepFlags. Lines ending with a
+// backslash or within quotes continue on the following line, and lines empty
+// or only a comment are skipped. Returns a LineResult for each line executed,
+// and any errors, each a LineError, joined. Execution stops at the first line
+// failing unless set by SetScriptContinue, at the end of the provided
+// context.Context, or at an error reading.
+func (e *executer) ExecuteScript(ctx context.Context, in io.Reader) ([]*LineResult, error) {
+	defer e.resetLines()()
+
+	var ret []*LineResult
+	var errs []error
+	s := bufio.NewScanner(in)
+	n := 0
+	for s.Scan() {
+		n++
+		start, line := n, s.Text()
+		args, more := tokenize(line)
+		for more && s.Scan() {
+			n++
+			line += "\n" + s.Text()
+			args, more = tokenize(line)
+		}
+		if len(args) == 0 && !more {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, &LineError{start, err})
+			break
+		}
+		r, err := e.ExecuteLine(ctx, line)
+		ret = append(ret, &LineResult{start, line, r, err})
+		if err != nil {
+			errs = append(errs, &LineError{start, err})
+			if !e.proceed {
+				break
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return ret, errors.Join(errs...)
+}
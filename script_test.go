@@ -0,0 +1,80 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const testScript = `# a script
+run -count 2 -match abc
+
+prep run \
+  -count 3
+fail
+run
+`
+
+func TestExecuteScript(t *testing.T) {
+	b := new(bytes.Buffer)
+	f := errorFlipper(b)
+	for _, cmd := range f.GetGroup("run").Commands {
+		if cmd.Tag() == "run" {
+			cmd.(*command).RegexVar("match", "a lower case word", func(s string, rs ...*regexp.Regexp) error {
+				if !rs[0].MatchString(s) {
+					return fmt.Errorf("no match")
+				}
+				return nil
+			}, "^[a-z]+$")
+		}
+	}
+
+	r, err := f.ExecuteLine(context.Background(), `run -count '4'`)
+	if err != nil || r.Status != ExitSuccess || r.Ran[0].(*command).Lookup("count").Value.String() != "4" {
+		t.Errorf("unexpected result %+v, %v", r, err)
+	}
+	r, err = f.ExecuteLine(context.Background(), `run "-count`)
+	if !errors.Is(err, ErrUnterminated) || r.Status != ExitUsageError || r.Code != 2 {
+		t.Errorf("expected ErrUnterminated, got %+v, %v", r, err)
+	}
+
+	rs, err := f.ExecuteScript(context.Background(), strings.NewReader(testScript))
+	var le *LineError
+	if !errors.As(err, &le) || le.Line != 6 || !errors.Is(err, errBroken) {
+		t.Errorf("expected line 6 error, got %v", err)
+	}
+	if len(rs) != 3 || rs[0].Line != 2 || rs[1].Line != 4 || rs[2].Line != 6 || rs[2].Err == nil {
+		t.Fatalf("unexpected line results %+v", rs)
+	}
+	if rs[1].Text != "prep run \\\n  -count 3" || len(rs[1].Result.Ran) != 2 {
+		t.Errorf("unexpected continued line result %+v", rs[1])
+	}
+
+	f.SetScriptContinue(true)
+	rs, err = f.ExecuteScript(context.Background(), strings.NewReader(testScript))
+	if err == nil || len(rs) != 4 || rs[3].Err != nil || rs[3].Result.Status != ExitSuccess {
+		t.Errorf("expected execution to continue, got %+v, %v", rs, err)
+	}
+	if v := rs[3].Result.Ran[0].(*command).Lookup("count").Value.String(); v != "1" {
+		t.Errorf("expected flags reset between lines, got count %s", v)
+	}
+	if f.executer.reset {
+		t.Error("expected flag reset to be restored after the script")
+	}
+
+	var lines LineExecuter = f
+	fa := NewFactory(func() Flipper { return errorFlipper(new(bytes.Buffer)) })
+	if r, err := fa.ExecuteLine(context.Background(), "run -count 2"); err != nil || r.Status != ExitSuccess {
+		t.Errorf("unexpected factory line result %+v, %v", r, err)
+	}
+
+	lines.SetKeepFlags(true)
+	rs, err = lines.ExecuteScript(context.Background(), strings.NewReader("run -count 6\nrun\n"))
+	if err != nil || len(rs) != 2 || rs[1].Result.Ran[0].(*command).Lookup("count").Value.String() != "6" {
+		t.Errorf("expected flags kept between lines, got %+v, %v", rs, err)
+	}
+}
@@ -0,0 +1,188 @@
+package flip
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// An optional interface of a Flipper for interactive execution of command
+// lines, implemented by Flippers of New.
+type Sheller interface {
+	Shell(context.Context, io.Reader) error
+	SetKeepFlags(bool)
+}
+
+var shellWords = []string{"exit", "help", "history", "quit"}
+
+// Reads command lines from the provided io.Reader until its end, a line of
+// exit or quit, or the end of the provided context.Context, writing a prompt
+// before and the exit code after each line to the Instructer output. Each line
+// is split into arguments as by Tokenize, reading following lines for an
+// unterminated line, and executed as by ExecuteE, with every FlagSet reset to
+// its defaults unless set by SetKeepFlags. A line containing a tab writes
+// completions of the words before the tab instead. Unless they are Command
+// tags, help writes instruction for all or the following commands, history
+// writes the numbered lines executed, and !! or !n executes the last or nth
+// line again.
+func (f *flipper) Shell(ctx context.Context, in io.Reader) error {
+	defer f.executer.resetLines()()
+
+	o := f.Out()
+	var history []string
+	s := bufio.NewScanner(in)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		fmt.Fprintf(o, "%s> ", f.name)
+		if !s.Scan() {
+			fmt.Fprintln(o)
+			return s.Err()
+		}
+		line := s.Text()
+		if i := strings.Index(line, "\t"); i >= 0 {
+			f.shellComplete(o, line[:i])
+			continue
+		}
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "!") {
+			var ok bool
+			if line, ok = recall(history, line); !ok {
+				fmt.Fprintf(o, "%s: event not found\n", line)
+				continue
+			}
+			fmt.Fprintln(o, line)
+		}
+		args, more := tokenize(line)
+		for more {
+			fmt.Fprint(o, "> ")
+			if !s.Scan() {
+				fmt.Fprintf(o, "\n%v\n", ErrUnterminated)
+				return s.Err()
+			}
+			line += "\n" + s.Text()
+			args, more = tokenize(line)
+		}
+		if len(args) == 0 {
+			continue
+		}
+		history = append(history, line)
+		if f.iscmdfn(nil, args[0]) == nil {
+			switch args[0] {
+			case "exit", "quit":
+				return nil
+			case "help":
+				f.shellHelp(ctx, args[1:])
+				continue
+			case "history":
+				for i, h := range history {
+					fmt.Fprintf(o, "%5d  %s\n", i+1, h)
+				}
+				continue
+			}
+		}
+		r, err := f.ExecuteE(ctx, append([]string{f.name}, args...))
+		if err != nil {
+			fmt.Fprintf(o, "[%d] %v\n", r.Code, err)
+			continue
+		}
+		fmt.Fprintf(o, "[%d]\n", r.Code)
+	}
+}
+
+// Set Shell and ExecuteScript to keep flag values between lines, resetting
+// every FlagSet only if set by SetResetFlags.
+func (e *executer) SetKeepFlags(keep bool) {
+	e.keep = keep
+}
+
+// sets resetting every FlagSet before each line unless keeping flag values,
+// returning a function restoring the setting
+func (e *executer) resetLines() func() {
+	reset := e.reset
+	e.reset = reset || !e.keep
+	return func() { e.reset = reset }
+}
+
+// returns the history line referred to by !! or !n
+func recall(history []string, event string) (string, bool) {
+	n := len(history)
+	if event != "!!" {
+		i, err := strconv.Atoi(event[1:])
+		if err != nil {
+			return event, false
+		}
+		n = i
+	}
+	if n < 1 || n > len(history) {
+		return event, false
+	}
+	return history[n-1], true
+}
+
+func (f *flipper) shellComplete(o io.Writer, line string) {
+	words, _ := tokenize(line)
+	if len(words) == 0 || strings.HasSuffix(line, " ") {
+		words = append(words, "")
+	}
+	cs, _ := complete(f.Commander, append([]string{f.name}, words...))
+	if len(words) == 1 {
+		cs = append(cs, filterPrefix(shellWords, words[0])...)
+	}
+	if len(cs) > 0 {
+		fmt.Fprintln(o, strings.Join(cs, "  "))
+	}
+}
+
+func (f *flipper) shellHelp(ctx context.Context, tags []string) {
+	if len(tags) == 0 {
+		f.Instruction(ctx)
+		return
+	}
+	var cs []Command
+	for _, tag := range tags {
+		cs = append(cs, f.GetCommand(tag)...)
+	}
+	f.SubsetInstruction(cs...)(ctx)
+}
+
+type shell struct {
+	f       *flipper
+	in      io.Reader
+	running bool
+}
+
+func (s *shell) command() Command {
+	return NewCommand(
+		"",
+		"shell",
+		`Reads and executes command lines interactively.`,
+		1,
+		true,
+		func(c context.Context, a []string) (context.Context, ExitStatus) {
+			if s.running {
+				fmt.Fprintln(s.f.Out(), "already in a shell")
+				return c, ExitFailure
+			}
+			s.running = true
+			defer func() { s.running = false }()
+			if err := s.f.Shell(c, s.in); err != nil && err != c.Err() {
+				fmt.Fprintln(s.f.Out(), err)
+				return c, ExitIOError
+			}
+			return c, ExitSuccess
+		},
+		NewFlagSet("shell", ContinueOnError),
+	)
+}
+
+func (f *flipper) addShell() *flipper {
+	s := &shell{f, os.Stdin, false}
+	f.SetGroup("shell", -1000, s.command())
+	return f
+}
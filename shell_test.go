@@ -0,0 +1,90 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestShell(t *testing.T) {
+	b := new(bytes.Buffer)
+	var echoed []string
+	fs := NewFlagSet("echo", ContinueOnError)
+	n := fs.Int("n", 1, "repeat n times")
+	fs.RegexVar("match", "a lower case word", func(s string, rs ...*regexp.Regexp) error {
+		if !rs[0].MatchString(s) {
+			return fmt.Errorf("no match")
+		}
+		return nil
+	}, "^[a-z]+$")
+	fs.SetOut(b)
+	f := New("test")
+	f.SetOut(b)
+	f.SetGroup("run", 1,
+		NewCommand("", "echo", "echo arguments", 1, false,
+			func(c context.Context, a []string) (context.Context, ExitStatus) {
+				echoed = append(echoed, fmt.Sprintf("%d:%s", *n, strings.Join(fs.Args(), ",")))
+				return c, ExitSuccess
+			},
+			fs,
+		),
+		NewCommand("", "fail", "fail command", 2, false,
+			func(c context.Context, a []string) (context.Context, ExitStatus) {
+				return WithError(c, errBroken), ExitFailure
+			},
+			NewFlagSet("fail", ContinueOnError),
+		),
+	)
+
+	in := strings.NewReader(strings.Join([]string{
+		`echo -n 2 'a b' "c\"d"`,
+		`echo`,
+		`fail`,
+		"e\t",
+		"echo -\t",
+		`history`,
+		`!1`,
+		`!9`,
+		`help echo`,
+		`exit`,
+		`echo after`,
+	}, "\n"))
+	if err := f.Shell(context.Background(), in); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{`2:a b,c"d`, "1:", `2:a b,c"d`}
+	if strings.Join(echoed, "|") != strings.Join(expect, "|") {
+		t.Errorf("expected %q, got %q", expect, echoed)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"test> ",
+		"[0]\n",
+		"[1] command fail failed: broken\n",
+		"echo  exit\n",
+		"-n\n",
+		"    3  fail\n",
+		"!9: event not found\n",
+		"echo arguments",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if f.executer.reset {
+		t.Error("expected flag reset to be restored after the shell")
+	}
+
+	echoed = nil
+	f.SetKeepFlags(true)
+	if err := f.Shell(context.Background(), strings.NewReader("echo -n 3 -match abc\necho\n")); err != nil {
+		t.Fatal(err)
+	}
+	if expect := []string{"3:", "3:"}; strings.Join(echoed, "|") != strings.Join(expect, "|") {
+		t.Errorf("expected flags kept %q, got %q", expect, echoed)
+	}
+}
@@ -0,0 +1,99 @@
+package flip
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// An optional interface of a Flipper for opting in to signal handling of
+// execution, implemented by Flippers of New.
+type Signaler interface {
+	HandleSignals(...os.Signal)
+}
+
+// An error type returned from ExecuteE when execution is interrupted by a
+// handled signal, wrapping context.Canceled.
+type SignalError struct {
+	Signal os.Signal
+}
+
+// Returns the SignalError as a string.
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("interrupted by signal %v", e.Signal)
+}
+
+// Returns context.Canceled, the cause of the execution context.Context
+// ending.
+func (e *SignalError) Unwrap() error {
+	return context.Canceled
+}
+
+// the exit code of a signal, 128 and the signal number as by a shell
+func signalCode(s os.Signal) int {
+	if n, ok := s.(syscall.Signal); ok {
+		return 128 + int(n)
+	}
+	return 128
+}
+
+// forceExit exits the process on a repeated signal, replaceable for testing
+var forceExit = os.Exit
+
+// Handles the provided signals, SIGINT and SIGTERM if none, during
+// execution. The context.Context provided to Commands is canceled on the
+// first signal, no further Commands are executed, and execution ends with
+// ExitInterrupted, running its Cleanup functions, a SignalError, and an exit
+// code of 128 and the signal number. A second signal exits the process
+// immediately with that exit code.
+func (e *executer) HandleSignals(sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	e.signals = sigs
+}
+
+// returns a context.Context canceled on a handled signal, a function
+// returning any signal received, and a function to stop signal handling
+func (e *executer) notify(ctx context.Context) (context.Context, func() os.Signal, func()) {
+	if len(e.signals) == 0 {
+		return ctx, func() os.Signal { return nil }, func() {}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	ch := make(chan os.Signal, 2)
+	got := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, e.signals...)
+	go func() {
+		select {
+		case s := <-ch:
+			got <- s
+			cancel()
+		case <-done:
+			return
+		}
+		select {
+		case s := <-ch:
+			forceExit(signalCode(s))
+		case <-done:
+		}
+	}()
+	var received os.Signal
+	sigfn := func() os.Signal {
+		if received == nil {
+			select {
+			case received = <-got:
+			default:
+			}
+		}
+		return received
+	}
+	stop := func() {
+		signal.Stop(ch)
+		close(done)
+		cancel()
+	}
+	return ctx, sigfn, stop
+}
@@ -0,0 +1,79 @@
+package flip
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleSignals(t *testing.T) {
+	b := new(bytes.Buffer)
+	f := New("test")
+	f.SetOut(b)
+	var ran []string
+	started := make(chan struct{})
+	exited := make(chan int, 1)
+	forceExit = func(code int) { exited <- code }
+	defer func() { forceExit = os.Exit }()
+	f.SetGroup("wait", 1,
+		NewCommand("", "wait", "waits for cancellation", 1, false,
+			func(c context.Context, a []string) (context.Context, ExitStatus) {
+				ran = append(ran, "wait")
+				close(started)
+				select {
+				case <-c.Done():
+				case <-time.After(5 * time.Second):
+					t.Error("context not canceled by signal")
+					return c, ExitNo
+				}
+				syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+				select {
+				case code := <-exited:
+					if code != 128+int(syscall.SIGUSR1) {
+						t.Errorf("unexpected forced exit code %d", code)
+					}
+				case <-time.After(5 * time.Second):
+					t.Error("no forced exit on second signal")
+				}
+				return c, ExitNo
+			},
+			NewFlagSet("wait", ContinueOnError),
+		),
+		NewCommand("", "after", "never runs", 2, false,
+			func(c context.Context, a []string) (context.Context, ExitStatus) {
+				ran = append(ran, "after")
+				return c, ExitSuccess
+			},
+			NewFlagSet("after", ContinueOnError),
+		),
+	)
+	var interrupted bool
+	f.SetCleanup(ExitInterrupted, func(context.Context) { interrupted = true })
+	f.HandleSignals(syscall.SIGUSR1)
+
+	go func() {
+		<-started
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	}()
+	r, err := f.ExecuteE(context.Background(), []string{"test", "wait", "after"})
+
+	var se *SignalError
+	if !errors.As(err, &se) || se.Signal != syscall.SIGUSR1 || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected signal error, got %v", err)
+	}
+	if r.Status != ExitInterrupted || r.Signal != syscall.SIGUSR1 || r.Code != 128+int(syscall.SIGUSR1) {
+		t.Errorf("unexpected interrupted result %+v", r)
+	}
+	if !interrupted || len(ran) != 1 {
+		t.Errorf("expected interrupted cleanup after wait only, got %v, %v", interrupted, ran)
+	}
+
+	r, err = f.ExecuteE(context.Background(), []string{"test", "after"})
+	if err != nil || r.Status != ExitSuccess || r.Signal != nil {
+		t.Errorf("unexpected result without signal %+v, %v", r, err)
+	}
+}
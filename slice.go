@@ -0,0 +1,695 @@
+package flip
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type repeatableFlag interface {
+	Value
+	IsRepeatable() bool
+	reDefault()
+}
+
+// A Value accumulating across repeated setting of the flag, where the first
+// setting following a default replaces the default.
+type sliceValue struct {
+	kind  string
+	sep   string
+	set   bool
+	add   setFn
+	clear func()
+	gfn   getFn
+}
+
+// Value interface Set function for internal type sliceValue, splitting the
+// provided string by any separator set.
+func (v *sliceValue) Set(s string) error {
+	if !v.set {
+		v.clear()
+		v.set = true
+	}
+	spl := []string{s}
+	if v.sep != "" {
+		spl = strings.Split(s, v.sep)
+	}
+	for _, n := range spl {
+		if err := v.add(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Value interface Get function for internal type sliceValue
+func (v *sliceValue) Get() interface{} {
+	return v.gfn()
+}
+
+// Value interface String function for internal type sliceValue
+func (v *sliceValue) String() string {
+	if m, ok := v.Get().(map[string]string); ok {
+		return mapString(m)
+	}
+	return fmt.Sprintf("%v", v.Get())
+}
+
+// internal repeatableFlag interface IsRepeatable function for internal type sliceValue
+func (v *sliceValue) IsRepeatable() bool { return true }
+
+func (v *sliceValue) reDefault() { v.set = false }
+
+// Sets a separator (e.g. ",") splitting each value of the previously defined
+// slice flag of the provided name into multiple values, so that -include a,b
+// is equivalent to -include a -include b. This will panic for an undefined or
+// non slice flag.
+func (f *FlagSet) SetSeparator(name, sep string) {
+	flag, ok := f.formal[name]
+	if ok {
+		if sv, ok := flag.Value.(*sliceValue); ok {
+			sv.sep = sep
+			return
+		}
+	}
+	msg := fmt.Sprintf("%s slice flag not defined for separator: %s", f.name, name)
+	fmt.Fprintln(f.Out(), msg)
+	panic(msg)
+}
+
+func newStringSliceValue(val []string, p *[]string) *sliceValue {
+	*p = append([]string{}, val...)
+	return &sliceValue{
+		"string",
+		"",
+		false,
+		func(n string) error {
+			*p = append(*p, n)
+			return nil
+		},
+		func() { *p = nil },
+		func() interface{} { return *p },
+	}
+}
+
+func newIntSliceValue(val []int, p *[]int) *sliceValue {
+	*p = append([]int{}, val...)
+	return &sliceValue{
+		"int",
+		"",
+		false,
+		func(n string) error {
+			s, err := strconv.ParseInt(n, 0, 64)
+			if err != nil {
+				return err
+			}
+			*p = append(*p, int(s))
+			return nil
+		},
+		func() { *p = nil },
+		func() interface{} { return *p },
+	}
+}
+
+func newInt64SliceValue(val []int64, p *[]int64) *sliceValue {
+	*p = append([]int64{}, val...)
+	return &sliceValue{
+		"int64",
+		"",
+		false,
+		func(n string) error {
+			s, err := strconv.ParseInt(n, 0, 64)
+			if err != nil {
+				return err
+			}
+			*p = append(*p, s)
+			return nil
+		},
+		func() { *p = nil },
+		func() interface{} { return *p },
+	}
+}
+
+func newUintSliceValue(val []uint, p *[]uint) *sliceValue {
+	*p = append([]uint{}, val...)
+	return &sliceValue{
+		"uint",
+		"",
+		false,
+		func(n string) error {
+			s, err := strconv.ParseUint(n, 0, 64)
+			if err != nil {
+				return err
+			}
+			*p = append(*p, uint(s))
+			return nil
+		},
+		func() { *p = nil },
+		func() interface{} { return *p },
+	}
+}
+
+func newUint64SliceValue(val []uint64, p *[]uint64) *sliceValue {
+	*p = append([]uint64{}, val...)
+	return &sliceValue{
+		"uint64",
+		"",
+		false,
+		func(n string) error {
+			s, err := strconv.ParseUint(n, 0, 64)
+			if err != nil {
+				return err
+			}
+			*p = append(*p, s)
+			return nil
+		},
+		func() { *p = nil },
+		func() interface{} { return *p },
+	}
+}
+
+func newFloat64SliceValue(val []float64, p *[]float64) *sliceValue {
+	*p = append([]float64{}, val...)
+	return &sliceValue{
+		"float64",
+		"",
+		false,
+		func(n string) error {
+			s, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return err
+			}
+			*p = append(*p, s)
+			return nil
+		},
+		func() { *p = nil },
+		func() interface{} { return *p },
+	}
+}
+
+func newDurationSliceValue(val []time.Duration, p *[]time.Duration) *sliceValue {
+	*p = append([]time.Duration{}, val...)
+	return &sliceValue{
+		"duration",
+		"",
+		false,
+		func(n string) error {
+			s, err := time.ParseDuration(n)
+			if err != nil {
+				return err
+			}
+			*p = append(*p, s)
+			return nil
+		},
+		func() { *p = nil },
+		func() interface{} { return *p },
+	}
+}
+
+// An interface for containers backing a repeatable string flag.
+type StringSliceContain interface {
+	SetStrings(string, []string)
+	ToStrings(string) []string
+}
+
+func stringSliceContainValue(key string, value []string, v StringSliceContain) *sliceValue {
+	v.SetStrings(key, append([]string{}, value...))
+	return &sliceValue{
+		"string",
+		"",
+		false,
+		func(n string) error {
+			v.SetStrings(key, append(v.ToStrings(key), n))
+			return nil
+		},
+		func() { v.SetStrings(key, nil) },
+		func() interface{} {
+			return v.ToStrings(key)
+		},
+	}
+}
+
+// An interface for containers backing a repeatable int flag.
+type IntSliceContain interface {
+	SetInt64s(string, []int64)
+	ToInt64s(string) []int64
+}
+
+func intSliceContainValue(key string, value []int64, v IntSliceContain) *sliceValue {
+	v.SetInt64s(key, append([]int64{}, value...))
+	return &sliceValue{
+		"int",
+		"",
+		false,
+		func(n string) error {
+			s, err := strconv.ParseInt(n, 0, 64)
+			if err != nil {
+				return err
+			}
+			v.SetInt64s(key, append(v.ToInt64s(key), s))
+			return nil
+		},
+		func() { v.SetInt64s(key, nil) },
+		func() interface{} {
+			var ret []int
+			for _, i := range v.ToInt64s(key) {
+				ret = append(ret, int(i))
+			}
+			return ret
+		},
+	}
+}
+
+// An interface for containers backing a repeatable int64 flag.
+type Int64SliceContain interface {
+	SetInt64s(string, []int64)
+	ToInt64s(string) []int64
+}
+
+func int64SliceContainValue(key string, value []int64, v Int64SliceContain) *sliceValue {
+	v.SetInt64s(key, append([]int64{}, value...))
+	return &sliceValue{
+		"int64",
+		"",
+		false,
+		func(n string) error {
+			s, err := strconv.ParseInt(n, 0, 64)
+			if err != nil {
+				return err
+			}
+			v.SetInt64s(key, append(v.ToInt64s(key), s))
+			return nil
+		},
+		func() { v.SetInt64s(key, nil) },
+		func() interface{} {
+			return v.ToInt64s(key)
+		},
+	}
+}
+
+// An interface for containers backing a repeatable uint flag.
+type UintSliceContain interface {
+	SetUint64s(string, []uint64)
+	ToUint64s(string) []uint64
+}
+
+func uintSliceContainValue(key string, value []uint64, v UintSliceContain) *sliceValue {
+	v.SetUint64s(key, append([]uint64{}, value...))
+	return &sliceValue{
+		"uint",
+		"",
+		false,
+		func(n string) error {
+			s, err := strconv.ParseUint(n, 0, 64)
+			if err != nil {
+				return err
+			}
+			v.SetUint64s(key, append(v.ToUint64s(key), s))
+			return nil
+		},
+		func() { v.SetUint64s(key, nil) },
+		func() interface{} {
+			var ret []uint
+			for _, i := range v.ToUint64s(key) {
+				ret = append(ret, uint(i))
+			}
+			return ret
+		},
+	}
+}
+
+// An interface for containers backing a repeatable uint64 flag.
+type Uint64SliceContain interface {
+	SetUint64s(string, []uint64)
+	ToUint64s(string) []uint64
+}
+
+func uint64SliceContainValue(key string, value []uint64, v Uint64SliceContain) *sliceValue {
+	v.SetUint64s(key, append([]uint64{}, value...))
+	return &sliceValue{
+		"uint64",
+		"",
+		false,
+		func(n string) error {
+			s, err := strconv.ParseUint(n, 0, 64)
+			if err != nil {
+				return err
+			}
+			v.SetUint64s(key, append(v.ToUint64s(key), s))
+			return nil
+		},
+		func() { v.SetUint64s(key, nil) },
+		func() interface{} {
+			return v.ToUint64s(key)
+		},
+	}
+}
+
+// An interface for containers backing a repeatable float64 flag.
+type Float64SliceContain interface {
+	SetFloat64s(string, []float64)
+	ToFloat64s(string) []float64
+}
+
+func float64SliceContainValue(key string, value []float64, v Float64SliceContain) *sliceValue {
+	v.SetFloat64s(key, append([]float64{}, value...))
+	return &sliceValue{
+		"float64",
+		"",
+		false,
+		func(n string) error {
+			s, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return err
+			}
+			v.SetFloat64s(key, append(v.ToFloat64s(key), s))
+			return nil
+		},
+		func() { v.SetFloat64s(key, nil) },
+		func() interface{} {
+			return v.ToFloat64s(key)
+		},
+	}
+}
+
+func durationSliceContainValue(key string, value []string, v StringSliceContain) *sliceValue {
+	v.SetStrings(key, append([]string{}, value...))
+	return &sliceValue{
+		"duration",
+		"",
+		false,
+		func(n string) error {
+			if _, err := time.ParseDuration(n); err != nil {
+				return err
+			}
+			v.SetStrings(key, append(v.ToStrings(key), n))
+			return nil
+		},
+		func() { v.SetStrings(key, nil) },
+		func() interface{} {
+			var ret []time.Duration
+			for _, s := range v.ToStrings(key) {
+				d, err := time.ParseDuration(s)
+				if err != nil {
+					return err
+				}
+				ret = append(ret, d)
+			}
+			return ret
+		},
+	}
+}
+
+// Defines a repeatable string flag accumulating into the provided slice pointer.
+func (f *FlagSet) StringSliceVar(p *[]string, name string, value []string, usage string) {
+	f.Var(newStringSliceValue(value, p), name, usage)
+}
+
+// Defines a repeatable string flag, returning a pointer to the accumulated slice.
+func (f *FlagSet) StringSlice(name string, value []string, usage string) *[]string {
+	p := new([]string)
+	f.StringSliceVar(p, name, value, usage)
+	return p
+}
+
+// Defines a repeatable string flag accumulating in the provided container by key.
+func (f *FlagSet) StringSliceContainVar(d StringSliceContain, name, key string, value []string, usage string) {
+	f.Var(stringSliceContainValue(key, value, d), name, usage)
+}
+
+// Defines a repeatable string flag accumulating in the provided container by key,
+// returning the container.
+func (f *FlagSet) StringSliceContain(d StringSliceContain, name, key, usage string) StringSliceContain {
+	f.StringSliceContainVar(d, name, key, nil, usage)
+	return d
+}
+
+// Defines a repeatable int flag accumulating into the provided slice pointer.
+func (f *FlagSet) IntSliceVar(p *[]int, name string, value []int, usage string) {
+	f.Var(newIntSliceValue(value, p), name, usage)
+}
+
+// Defines a repeatable int flag, returning a pointer to the accumulated slice.
+func (f *FlagSet) IntSlice(name string, value []int, usage string) *[]int {
+	p := new([]int)
+	f.IntSliceVar(p, name, value, usage)
+	return p
+}
+
+// Defines a repeatable int flag accumulating in the provided container by key.
+func (f *FlagSet) IntSliceContainVar(d IntSliceContain, name, key string, value []int64, usage string) {
+	f.Var(intSliceContainValue(key, value, d), name, usage)
+}
+
+// Defines a repeatable int flag accumulating in the provided container by key,
+// returning the container.
+func (f *FlagSet) IntSliceContain(d IntSliceContain, name, key, usage string) IntSliceContain {
+	f.IntSliceContainVar(d, name, key, nil, usage)
+	return d
+}
+
+// Defines a repeatable int64 flag accumulating into the provided slice pointer.
+func (f *FlagSet) Int64SliceVar(p *[]int64, name string, value []int64, usage string) {
+	f.Var(newInt64SliceValue(value, p), name, usage)
+}
+
+// Defines a repeatable int64 flag, returning a pointer to the accumulated slice.
+func (f *FlagSet) Int64Slice(name string, value []int64, usage string) *[]int64 {
+	p := new([]int64)
+	f.Int64SliceVar(p, name, value, usage)
+	return p
+}
+
+// Defines a repeatable int64 flag accumulating in the provided container by key.
+func (f *FlagSet) Int64SliceContainVar(d Int64SliceContain, name, key string, value []int64, usage string) {
+	f.Var(int64SliceContainValue(key, value, d), name, usage)
+}
+
+// Defines a repeatable int64 flag accumulating in the provided container by key,
+// returning the container.
+func (f *FlagSet) Int64SliceContain(d Int64SliceContain, name, key, usage string) Int64SliceContain {
+	f.Int64SliceContainVar(d, name, key, nil, usage)
+	return d
+}
+
+// Defines a repeatable uint flag accumulating into the provided slice pointer.
+func (f *FlagSet) UintSliceVar(p *[]uint, name string, value []uint, usage string) {
+	f.Var(newUintSliceValue(value, p), name, usage)
+}
+
+// Defines a repeatable uint flag, returning a pointer to the accumulated slice.
+func (f *FlagSet) UintSlice(name string, value []uint, usage string) *[]uint {
+	p := new([]uint)
+	f.UintSliceVar(p, name, value, usage)
+	return p
+}
+
+// Defines a repeatable uint flag accumulating in the provided container by key.
+func (f *FlagSet) UintSliceContainVar(d UintSliceContain, name, key string, value []uint64, usage string) {
+	f.Var(uintSliceContainValue(key, value, d), name, usage)
+}
+
+// Defines a repeatable uint flag accumulating in the provided container by key,
+// returning the container.
+func (f *FlagSet) UintSliceContain(d UintSliceContain, name, key, usage string) UintSliceContain {
+	f.UintSliceContainVar(d, name, key, nil, usage)
+	return d
+}
+
+// Defines a repeatable uint64 flag accumulating into the provided slice pointer.
+func (f *FlagSet) Uint64SliceVar(p *[]uint64, name string, value []uint64, usage string) {
+	f.Var(newUint64SliceValue(value, p), name, usage)
+}
+
+// Defines a repeatable uint64 flag, returning a pointer to the accumulated slice.
+func (f *FlagSet) Uint64Slice(name string, value []uint64, usage string) *[]uint64 {
+	p := new([]uint64)
+	f.Uint64SliceVar(p, name, value, usage)
+	return p
+}
+
+// Defines a repeatable uint64 flag accumulating in the provided container by key.
+func (f *FlagSet) Uint64SliceContainVar(d Uint64SliceContain, name, key string, value []uint64, usage string) {
+	f.Var(uint64SliceContainValue(key, value, d), name, usage)
+}
+
+// Defines a repeatable uint64 flag accumulating in the provided container by key,
+// returning the container.
+func (f *FlagSet) Uint64SliceContain(d Uint64SliceContain, name, key, usage string) Uint64SliceContain {
+	f.Uint64SliceContainVar(d, name, key, nil, usage)
+	return d
+}
+
+// Defines a repeatable float64 flag accumulating into the provided slice pointer.
+func (f *FlagSet) Float64SliceVar(p *[]float64, name string, value []float64, usage string) {
+	f.Var(newFloat64SliceValue(value, p), name, usage)
+}
+
+// Defines a repeatable float64 flag, returning a pointer to the accumulated slice.
+func (f *FlagSet) Float64Slice(name string, value []float64, usage string) *[]float64 {
+	p := new([]float64)
+	f.Float64SliceVar(p, name, value, usage)
+	return p
+}
+
+// Defines a repeatable float64 flag accumulating in the provided container by key.
+func (f *FlagSet) Float64SliceContainVar(d Float64SliceContain, name, key string, value []float64, usage string) {
+	f.Var(float64SliceContainValue(key, value, d), name, usage)
+}
+
+// Defines a repeatable float64 flag accumulating in the provided container by key,
+// returning the container.
+func (f *FlagSet) Float64SliceContain(d Float64SliceContain, name, key, usage string) Float64SliceContain {
+	f.Float64SliceContainVar(d, name, key, nil, usage)
+	return d
+}
+
+// Defines a repeatable duration flag accumulating into the provided slice pointer.
+func (f *FlagSet) DurationSliceVar(p *[]time.Duration, name string, value []time.Duration, usage string) {
+	f.Var(newDurationSliceValue(value, p), name, usage)
+}
+
+// Defines a repeatable duration flag, returning a pointer to the accumulated slice.
+func (f *FlagSet) DurationSlice(name string, value []time.Duration, usage string) *[]time.Duration {
+	p := new([]time.Duration)
+	f.DurationSliceVar(p, name, value, usage)
+	return p
+}
+
+// Defines a repeatable duration flag accumulating in the provided container by key.
+func (f *FlagSet) DurationSliceContainVar(d StringSliceContain, name, key string, value []string, usage string) {
+	f.Var(durationSliceContainValue(key, value, d), name, usage)
+}
+
+// Defines a repeatable duration flag accumulating in the provided container by key,
+// returning the container.
+func (f *FlagSet) DurationSliceContain(d StringSliceContain, name, key, usage string) StringSliceContain {
+	f.DurationSliceContainVar(d, name, key, nil, usage)
+	return d
+}
+
+func mapString(m map[string]string) string {
+	var ps []string
+	for k, v := range m {
+		ps = append(ps, k+"="+v)
+	}
+	sort.Strings(ps)
+	return fmt.Sprintf("[%s]", strings.Join(ps, " "))
+}
+
+// returns the elements of a slice or map flag Value as strings setting them,
+// or nil for any other Value
+func sliceElements(v Value) []string {
+	sv, ok := v.(*sliceValue)
+	if !ok {
+		return nil
+	}
+	var ret []string
+	rv := reflect.ValueOf(sv.Get())
+	switch rv.Kind() {
+	case reflect.Map:
+		for _, k := range rv.MapKeys() {
+			ret = append(ret, fmt.Sprintf("%v=%v", k, rv.MapIndex(k)))
+		}
+		sort.Strings(ret)
+	case reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			ret = append(ret, fmt.Sprintf("%v", rv.Index(i)))
+		}
+	}
+	return ret
+}
+
+func splitPair(n string) (string, string, error) {
+	spl := strings.SplitN(n, "=", 2)
+	if len(spl) != 2 || spl[0] == "" {
+		return "", "", fmt.Errorf("expected key=value pair")
+	}
+	return spl[0], spl[1], nil
+}
+
+func newStringMapValue(val map[string]string, p *map[string]string) *sliceValue {
+	*p = copyMap(val)
+	return &sliceValue{
+		"map",
+		"",
+		false,
+		func(n string) error {
+			k, v, err := splitPair(n)
+			if err != nil {
+				return err
+			}
+			(*p)[k] = v
+			return nil
+		},
+		func() { *p = make(map[string]string) },
+		func() interface{} { return *p },
+	}
+}
+
+// An interface for containers backing a repeatable key=value flag.
+type MapContain interface {
+	SetStringMap(string, map[string]string)
+	ToStringMap(string) map[string]string
+}
+
+func copyMap(m map[string]string) map[string]string {
+	c := make(map[string]string)
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func mapContainValue(key string, value map[string]string, v MapContain) *sliceValue {
+	v.SetStringMap(key, copyMap(value))
+	return &sliceValue{
+		"map",
+		"",
+		false,
+		func(n string) error {
+			k, val, err := splitPair(n)
+			if err != nil {
+				return err
+			}
+			m := copyMap(v.ToStringMap(key))
+			m[k] = val
+			v.SetStringMap(key, m)
+			return nil
+		},
+		func() { v.SetStringMap(key, make(map[string]string)) },
+		func() interface{} {
+			return v.ToStringMap(key)
+		},
+	}
+}
+
+// Defines a repeatable key=value flag (e.g. -label env=prod -label team=infra)
+// accumulating into the provided map pointer.
+func (f *FlagSet) StringMapVar(p *map[string]string, name string, value map[string]string, usage string) {
+	f.Var(newStringMapValue(value, p), name, usage)
+}
+
+// Defines a repeatable key=value flag, returning a pointer to the accumulated
+// map.
+func (f *FlagSet) StringMap(name string, value map[string]string, usage string) *map[string]string {
+	p := new(map[string]string)
+	f.StringMapVar(p, name, value, usage)
+	return p
+}
+
+// Defines a repeatable key=value flag accumulating in the provided container
+// by key.
+func (f *FlagSet) MapContainVar(d MapContain, name, key string, value map[string]string, usage string) {
+	f.Var(mapContainValue(key, value, d), name, usage)
+}
+
+// Defines a repeatable key=value flag accumulating in the provided container
+// by key, returning the container.
+func (f *FlagSet) MapContain(d MapContain, name, key, usage string) MapContain {
+	f.MapContainVar(d, name, key, nil, usage)
+	return d
+}
@@ -0,0 +1,89 @@
+package flip
+
+import (
+	"errors"
+	"strings"
+)
+
+// The error returned from Tokenize for a string ending within quotes or with
+// a backslash, i.e. continuing on a following line.
+var ErrUnterminated = errors.New("unterminated quote or escape")
+
+// Splits the provided string into arguments as a shell would: at unquoted
+// white space, with single quotes preserving their content literally, double
+// quotes preserving their content except a backslash escaping a double
+// quote, backslash, or newline, and a backslash otherwise escaping any
+// character. A backslash before a newline continues the line, and a # at the
+// start of a word comments out the rest of the line. No expansion of
+// variables, globs, or other shell syntax is done. Returns ErrUnterminated
+// for a string ending within quotes or with a backslash.
+func Tokenize(s string) ([]string, error) {
+	args, more := tokenize(s)
+	if more {
+		return nil, ErrUnterminated
+	}
+	return args, nil
+}
+
+// returns arguments of the provided string, and true if the string ends
+// within quotes or with a backslash
+func tokenize(s string) ([]string, bool) {
+	var ret []string
+	var b strings.Builder
+	in := false
+	var quote rune
+	escaped := false
+	comment := false
+	for _, r := range s {
+		switch {
+		case comment:
+			if r == '\n' {
+				comment = false
+			}
+		case escaped:
+			escaped = false
+			if r == '\n' {
+				continue
+			}
+			if quote == '"' && r != '"' && r != '\\' {
+				b.WriteRune('\\')
+			}
+			b.WriteRune(r)
+			in = true
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				b.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				b.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, in = r, true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if in {
+				ret = append(ret, b.String())
+				b.Reset()
+				in = false
+			}
+		case r == '#' && !in:
+			comment = true
+		default:
+			b.WriteRune(r)
+			in = true
+		}
+	}
+	if quote != 0 || escaped {
+		return ret, true
+	}
+	if in {
+		ret = append(ret, b.String())
+	}
+	return ret, false
+}
@@ -0,0 +1,38 @@
+package flip
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"  a  b\tc ", []string{"a", "b", "c"}},
+		{`'a b' "c d"`, []string{"a b", "c d"}},
+		{`'a\"b'`, []string{`a\"b`}},
+		{`"a\"b\\c\d"`, []string{`a"b\c\d`}},
+		{`a\ b \'c`, []string{"a b", "'c"}},
+		{`x''y "" ''`, []string{"xy", "", ""}},
+		{"a # comment 'b", []string{"a"}},
+		{"a#b '#c'", []string{"a#b", "#c"}},
+		{"a \\\nb", []string{"a", "b"}},
+		{"a\\\nb", []string{"ab"}},
+		{"'a\nb' \"c\\\nd\"", []string{"a\nb", "cd"}},
+		{"a # comment\nb", []string{"a", "b"}},
+	} {
+		got, err := Tokenize(tc.in)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Tokenize(%q): expected %q, got %q, %v", tc.in, tc.want, got, err)
+		}
+	}
+	for _, in := range []string{`'a`, `"a`, `a\`, `"a\"`} {
+		if _, err := Tokenize(in); !errors.Is(err, ErrUnterminated) {
+			t.Errorf("Tokenize(%q): expected ErrUnterminated, got %v", in, err)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package flip
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// An interface for flag Values naming their type for usage, e.g. "ip" for a
+// net.IP flag.
+type TypeNamer interface {
+	TypeName() string
+}
+
+// A function parsing a string to a value of type T.
+type ParseFunc[T any] func(string) (T, error)
+
+type typedValue[T any] struct {
+	p     *T
+	parse ParseFunc[T]
+	def   T
+}
+
+func newTypedValue[T any](val T, p *T, parse ParseFunc[T]) *typedValue[T] {
+	*p = val
+	return &typedValue[T]{p, parse, val}
+}
+
+// Value interface Set function for internal type typedValue
+func (v *typedValue[T]) Set(s string) error {
+	t, err := v.parse(s)
+	if err != nil {
+		return err
+	}
+	*v.p = t
+	return nil
+}
+
+// Value interface Get function for internal type typedValue
+func (v *typedValue[T]) Get() interface{} { return *v.p }
+
+// Value interface String function for internal type typedValue
+func (v *typedValue[T]) String() string { return formatTyped(v.p) }
+
+// records the default value, as the text of a value may not parse to it
+func (v *typedValue[T]) record() { v.def = *v.p }
+
+func (v *typedValue[T]) restore() error {
+	*v.p = v.def
+	return nil
+}
+
+// TypeNamer interface TypeName function for internal type typedValue
+func (v *typedValue[T]) TypeName() string { return typeName(reflect.TypeOf(v.p).Elem()) }
+
+// internal boolFlag interface IsBoolFlag function for internal type typedValue
+func (v *typedValue[T]) IsBoolFlag() bool {
+	return reflect.TypeOf(v.p).Elem().Kind() == reflect.Bool
+}
+
+// Defines a flag of any type T, setting the provided pointer. A nil ParseFunc
+// is derived from T: an encoding.TextUnmarshaler or encoding.BinaryUnmarshaler
+// implemented by *T (e.g. net.IP, netip.Prefix, url.URL, big.Int, time.Time),
+// or a string, boolean, integer, float or time.Duration kind. This will panic
+// for a nil ParseFunc where none can be derived.
+func TypedVar[T any](f *FlagSet, p *T, name string, value T, parse ParseFunc[T], usage string) {
+	if parse == nil {
+		parse = parseFor[T]()
+	}
+	if parse == nil {
+		msg := fmt.Sprintf("%s flag %s has no parse function for type %T", f.name, name, value)
+		fmt.Fprintln(f.Out(), msg)
+		panic(msg)
+	}
+	f.Var(newTypedValue(value, p, parse), name, usage)
+}
+
+// Defines a flag of any type T, returning a pointer. See TypedVar.
+func Typed[T any](f *FlagSet, name string, value T, parse ParseFunc[T], usage string) *T {
+	p := new(T)
+	TypedVar(f, p, name, value, parse, usage)
+	return p
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func parseFor[T any]() ParseFunc[T] {
+	pfn := parserFor(reflect.TypeOf((*T)(nil)).Elem())
+	if pfn == nil {
+		return nil
+	}
+	return func(s string) (T, error) {
+		var t T
+		err := pfn(s, reflect.ValueOf(&t).Elem())
+		return t, err
+	}
+}
+
+// returns a function parsing a string into an addressable reflect.Value of
+// the provided type, or nil for an unsupported type
+func parserFor(rt reflect.Type) func(string, reflect.Value) error {
+	pt := reflect.PtrTo(rt)
+	switch {
+	case pt.Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()):
+		return func(s string, v reflect.Value) error {
+			return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		}
+	case pt.Implements(reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()):
+		return func(s string, v reflect.Value) error {
+			return v.Addr().Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary([]byte(s))
+		}
+	}
+	switch rt.Kind() {
+	case reflect.String:
+		return func(s string, v reflect.Value) error {
+			v.SetString(s)
+			return nil
+		}
+	case reflect.Bool:
+		return func(s string, v reflect.Value) error {
+			b, err := strconv.ParseBool(s)
+			v.SetBool(b)
+			return err
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(s string, v reflect.Value) error {
+			if rt == durationType {
+				d, err := time.ParseDuration(s)
+				v.SetInt(int64(d))
+				return err
+			}
+			i, err := strconv.ParseInt(s, 0, rt.Bits())
+			v.SetInt(i)
+			return err
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(s string, v reflect.Value) error {
+			i, err := strconv.ParseUint(s, 0, rt.Bits())
+			v.SetUint(i)
+			return err
+		}
+	case reflect.Float32, reflect.Float64:
+		return func(s string, v reflect.Value) error {
+			fl, err := strconv.ParseFloat(s, rt.Bits())
+			v.SetFloat(fl)
+			return err
+		}
+	}
+	return nil
+}
+
+func formatTyped(p interface{}) string {
+	switch v := p.(type) {
+	case encoding.TextMarshaler:
+		if b, err := v.MarshalText(); err == nil {
+			return string(b)
+		}
+	case fmt.Stringer:
+		return v.String()
+	}
+	rv := reflect.ValueOf(p)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		return fmt.Sprintf("%v", rv.Elem().Interface())
+	}
+	return fmt.Sprintf("%v", p)
+}
+
+// derives a usage type name from the lower cased name of a type, integer,
+// unsigned integer and float kinds named as elsewhere in the package
+func typeName(t reflect.Type) string {
+	if t == durationType {
+		return "duration"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return ""
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "string"
+	}
+	if t.Name() != "" {
+		return strings.ToLower(t.Name())
+	}
+	return "value"
+}
+
+type textValue struct {
+	p encoding.TextUnmarshaler
+}
+
+// Value interface Set function for internal type textValue
+func (v *textValue) Set(s string) error {
+	return v.p.UnmarshalText([]byte(s))
+}
+
+// Value interface Get function for internal type textValue
+func (v *textValue) Get() interface{} { return v.p }
+
+// Value interface String function for internal type textValue
+func (v *textValue) String() string { return formatTyped(v.p) }
+
+// TypeNamer interface TypeName function for internal type textValue
+func (v *textValue) TypeName() string {
+	t := reflect.TypeOf(v.p)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return typeName(t)
+}
+
+// Defines a flag set by the provided encoding.TextUnmarshaler, defaulting to
+// the text of the provided encoding.TextMarshaler when not nil. This will
+// panic if the default cannot be marshaled into the provided
+// encoding.TextUnmarshaler.
+func (f *FlagSet) TextVar(p encoding.TextUnmarshaler, name string, value encoding.TextMarshaler, usage string) {
+	if value != nil {
+		b, err := value.MarshalText()
+		if err == nil {
+			err = p.UnmarshalText(b)
+		}
+		if err != nil {
+			msg := fmt.Sprintf("%s flag %s has invalid default: %v", f.name, name, err)
+			fmt.Fprintln(f.Out(), msg)
+			panic(msg)
+		}
+	}
+	f.Var(&textValue{p}, name, usage)
+}
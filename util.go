@@ -10,9 +10,8 @@ import (
 	"unsafe"
 )
 
-func failOnly(f *FlagSet, format string, a ...interface{}) error {
-	err := fmt.Errorf(format, a...)
-	fmt.Fprintln(f.Out(), err)
+func failOnly(f *FlagSet, err error) error {
+	fmt.Fprintf(f.Out(), "%v\n\n", err)
 	return err
 }
 
@@ -23,6 +22,12 @@ func failFmt(f *FlagSet, format string, a ...interface{}) error {
 	return err
 }
 
+func failErr(f *FlagSet, err error) error {
+	fmt.Fprintln(f.Out(), err)
+	f.Usage(f.Out())
+	return err
+}
+
 type color struct {
 	params []Attribute
 }